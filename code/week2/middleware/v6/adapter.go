@@ -0,0 +1,185 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 与标准库 net/http 的互操作
+// 框架中的响应并不直接写入 http.ResponseWriter 而是先记录在 Context.RespData 与 Context.RespStatusCode 中
+// 由 flashResp 在所有中间件执行完毕后统一写入 以便中间件能够观察和改写响应
+// 因此标准库的处理函数与中间件写入的响应同样需要先记录到上下文中 而非直接写入响应 否则会与 flashResp 重复写入
+
+// Handle 将标准库的 http.Handler 注册为路由的处理函数 例如:
+//
+//	s.Handle(http.MethodGet, "/metrics", promhttp.Handler())
+//
+// 处理函数写入的响应会先记录到上下文中 详见 WrapHandler
+func (s *HTTPServer) Handle(method string, path string, handler http.Handler) *Route {
+	s.addRoute(method, path, WrapHandler(handler))
+	return s.newRoute(method, path)
+}
+
+// Mount 将标准库的 http.Handler 挂载到给定的路由前缀上 所有HTTP动词下 前缀本身及其下的所有路径都由该处理函数处理
+// 调用处理函数前会从请求路径中去掉路由前缀 例如:
+//
+//	s.Mount("/debug/pprof", http.HandlerFunc(pprof.Index))
+//	s.Mount("/static", http.FileServer(http.Dir("./public"))) // /static/css/app.css 对应 ./public/css/app.css
+//
+// 前缀中可以包含参数路由段 例如 /tenant/:id/files
+// Tips: 挂载时会注册 前缀 与 前缀/* 两个路由 因此与这两个路由冲突的路由同样会与挂载冲突
+func (s *HTTPServer) Mount(prefix string, handler http.Handler) {
+	s.Group("/").Mount(prefix, handler)
+}
+
+// Handle 在分组下将标准库的 http.Handler 注册为路由的处理函数 详见 HTTPServer.Handle
+func (g *Group) Handle(method string, path string, handler http.Handler) *Route {
+	return g.addRoute(method, path, WrapHandler(handler))
+}
+
+// Mount 在分组下将标准库的 http.Handler 挂载到给定的路由前缀上 去掉的前缀为分组前缀与给定前缀的拼接 详见 HTTPServer.Mount
+func (g *Group) Mount(prefix string, handler http.Handler) {
+	wildcard := prefix + "/*"
+	if prefix == "/" {
+		wildcard = "/*"
+	}
+
+	handleFunc := stripPrefix(handler)
+	for _, method := range httpMethods {
+		g.addRoute(method, prefix, handleFunc)
+		g.addRoute(method, wildcard, handleFunc)
+	}
+}
+
+// stripPrefix 将标准库的 http.Handler 包装为去掉路由前缀后再处理请求的 HandleFunc
+// 去掉前缀后的路径即为挂载路由上通配符匹配的部分 命中前缀本身时为"/"
+// Tips: 此处不直接使用 http.StripPrefix 因为前缀中可能包含参数路由段 请求路径中的前缀与注册时的前缀并不相同
+func stripPrefix(handler http.Handler) HandleFunc {
+	return func(ctx *Context) {
+		rest, _ := ctx.PathParams.Get("*")
+		// 查找路由时忽略了请求路径末尾的"/" 此处需要保留 否则 http.FileServer 等处理函数会反复重定向目录
+		if rest != "" && strings.HasSuffix(ctx.Req.URL.Path, "/") {
+			rest += "/"
+		}
+
+		// Tips: 与 http.StripPrefix 相同 浅拷贝请求与URL 不修改原始请求
+		req := new(http.Request)
+		*req = *ctx.Req
+		req.URL = new(url.URL)
+		*req.URL = *ctx.Req.URL
+		req.URL.Path = "/" + rest
+		req.URL.RawPath = ""
+
+		serveHandler(ctx, handler, req)
+	}
+}
+
+// WrapHandler 将标准库的 http.Handler 包装为 HandleFunc
+// 处理函数写入的响应码与响应数据会记录到 Context.RespStatusCode 与 Context.RespData 中 由 flashResp 统一写入响应
+// 因此中间件依旧能够观察和改写处理函数的响应 处理函数设置的响应头则直接设置在响应上
+// Tips: 响应会被完整缓存在内存中 因此不适用于流式响应(例如SSE)
+func WrapHandler(handler http.Handler) HandleFunc {
+	return func(ctx *Context) {
+		serveHandler(ctx, handler, ctx.Req)
+	}
+}
+
+// serveHandler 使用给定的请求调用标准库的 http.Handler 并将响应记录到上下文中
+func serveHandler(ctx *Context, handler http.Handler, req *http.Request) {
+	handler.ServeHTTP(&respRecorder{ctx: ctx, header: ctx.Resp.Header()}, req)
+}
+
+// WrapMiddleware 将标准库风格的中间件 func(http.Handler) http.Handler 包装为 Middleware
+// 可以通过 ServerWithMiddleware 或 Use 注册 例如:
+//
+//	s := NewHTTPServer(ServerWithMiddleware(WrapMiddleware(cors.Default().Handler)))
+//
+// 后续处理函数记录在上下文中的响应会在返回时写入标准库中间件传入的 http.ResponseWriter 以便标准库中间件观察和改写响应(例如压缩)
+// 标准库中间件最终写入的响应同样记录回上下文中 由 flashResp 统一写入 因此响应不会被重复写入
+// 标准库中间件传入的 http.ResponseWriter 与 *http.Request 在后续处理函数中分别为 Context.Resp 与 Context.Req 返回时恢复
+func WrapMiddleware(middleware func(next http.Handler) http.Handler) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			resp, req := ctx.Resp, ctx.Req
+			defer func() {
+				ctx.Resp, ctx.Req = resp, req
+			}()
+
+			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx.Resp, ctx.Req = w, r
+				next(ctx)
+
+				// 将后续处理函数的响应写入w 写入的内容经过标准库中间件后会重新记录到上下文中
+				data, code := ctx.RespData, ctx.RespStatusCode
+				ctx.RespData, ctx.RespStatusCode = nil, 0
+				if code != 0 {
+					w.WriteHeader(code)
+				}
+				if len(data) != 0 {
+					_, _ = w.Write(data)
+				}
+			}))
+			handler.ServeHTTP(&respRecorder{ctx: ctx, header: resp.Header()}, req)
+		}
+	}
+}
+
+// ToStdMiddleware 将 Middleware 包装为标准库风格的中间件 func(http.Handler) http.Handler
+// 以便在不使用本框架路由的场景下(例如 http.ServeMux)复用本框架的中间件 例如:
+//
+//	mux := http.NewServeMux()
+//	http.ListenAndServe(":8080", ToStdMiddleware(accessLog)(mux))
+//
+// 被包装的 http.Handler 写入的响应会先记录到上下文中 中间件执行完毕后再统一写入响应
+// Tips: 上下文没有关联 HTTPServer 也没有命中任何路由 因此 Context.URLFor 与路径参数均不可用
+func ToStdMiddleware(middleware Middleware) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := &Context{
+				Req:  r,
+				Resp: w,
+			}
+			middleware(func(ctx *Context) {
+				serveHandler(ctx, next, ctx.Req)
+			})(ctx)
+
+			if ctx.RespStatusCode != 0 {
+				w.WriteHeader(ctx.RespStatusCode)
+			}
+			if len(ctx.RespData) != 0 {
+				_, _ = w.Write(ctx.RespData)
+			}
+		})
+	}
+}
+
+// respRecorder 将写入的响应码与响应数据记录到上下文中的 http.ResponseWriter
+type respRecorder struct {
+	ctx         *Context    // ctx 记录响应的上下文
+	header      http.Header // header 响应头 即实际响应的响应头
+	wroteHeader bool        // wroteHeader 是否已经写入了响应码 与 http.ResponseWriter 相同 仅第一次写入的响应码生效
+}
+
+// Header 返回响应头
+func (r *respRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader 将响应码记录到上下文中
+func (r *respRecorder) WriteHeader(statusCode int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.ctx.RespStatusCode = statusCode
+}
+
+// Write 将响应数据追加到上下文中 未写入响应码时视为写入了200
+func (r *respRecorder) Write(data []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.ctx.RespData = append(r.ctx.RespData, data...)
+	return len(data), nil
+}
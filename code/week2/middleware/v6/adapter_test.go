@@ -0,0 +1,209 @@
+package web
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+// TestHTTPServer_Handle 测试将标准库的 http.Handler 注册为路由的处理函数
+func TestHTTPServer_Handle(t *testing.T) {
+	// 路由级中间件能够观察到标准库处理函数写入的响应
+	var observed int
+	s := NewHTTPServer()
+	s.Use("/*", func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			observed = ctx.RespStatusCode
+		}
+	})
+	s.Handle(http.MethodPost, "/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "std")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created "))
+		_, _ = w.Write([]byte(r.URL.Path))
+	})).Name("create user")
+	s.Group("/api").Handle(http.MethodGet, "/ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/users", nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "std", recorder.Header().Get("X-Handler"))
+	assert.Equal(t, "created /users", recorder.Body.String())
+	assert.Equal(t, http.StatusCreated, observed)
+
+	request = httptest.NewRequest(http.MethodGet, "/api/ping", nil)
+	recorder = httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "pong", recorder.Body.String())
+	assert.Equal(t, http.StatusOK, observed)
+
+	u, err := s.URLFor("create user", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/users", u)
+}
+
+// TestHTTPServer_Mount 测试挂载标准库的 http.Handler 并去掉路由前缀
+func TestHTTPServer_Mount(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path))
+	})
+	files := fstest.MapFS{
+		"index.html":  {Data: []byte("home")},
+		"css/app.css": {Data: []byte("body{}")},
+	}
+
+	s := NewHTTPServer()
+	s.Mount("/echo", echo)
+	s.Group("/tenant/:id").Mount("/files", echo)
+	s.Mount("/static", http.FileServer(http.FS(files)))
+	s.GET("/echo/override", func(ctx *Context) {
+		ctx.RespData = []byte("override")
+	})
+
+	testCases := []struct {
+		name         string
+		method       string
+		target       string
+		wantCode     int
+		wantBody     string
+		wantLocation string
+	}{
+		{name: "prefix itself", method: http.MethodGet, target: "/echo", wantCode: http.StatusOK, wantBody: "GET /"},
+		{name: "prefix with trailing slash", method: http.MethodGet, target: "/echo/", wantCode: http.StatusOK, wantBody: "GET /"},
+		{name: "sub path", method: http.MethodPut, target: "/echo/a/b", wantCode: http.StatusOK, wantBody: "PUT /a/b"},
+		{name: "keep trailing slash", method: http.MethodDelete, target: "/echo/a/b/", wantCode: http.StatusOK, wantBody: "DELETE /a/b/"},
+		{name: "static route over mount", method: http.MethodGet, target: "/echo/override", wantCode: http.StatusOK, wantBody: "override"},
+		{name: "param prefix", method: http.MethodGet, target: "/tenant/12/files/a.txt", wantCode: http.StatusOK, wantBody: "GET /a.txt"},
+		{name: "file server", method: http.MethodGet, target: "/static/css/app.css", wantCode: http.StatusOK, wantBody: "body{}"},
+		{name: "file server index", method: http.MethodGet, target: "/static/", wantCode: http.StatusOK, wantBody: "home"},
+		{name: "file server directory redirect", method: http.MethodGet, target: "/static/css", wantCode: http.StatusMovedPermanently, wantLocation: "css/"},
+		{name: "file server not found", method: http.MethodGet, target: "/static/js/app.js", wantCode: http.StatusNotFound, wantBody: "404 page not found\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.target, nil)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantLocation, recorder.Header().Get("Location"))
+			if testCase.wantBody != "" {
+				assert.Equal(t, testCase.wantBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+// upperWriter 将响应数据转为大写的 http.ResponseWriter 用于模拟改写响应的标准库中间件(例如压缩)
+type upperWriter struct {
+	http.ResponseWriter
+}
+
+func (w upperWriter) Write(data []byte) (int, error) {
+	return w.ResponseWriter.Write(bytes.ToUpper(data))
+}
+
+// TestWrapMiddleware 测试将标准库风格的中间件包装为 Middleware
+func TestWrapMiddleware(t *testing.T) {
+	upper := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Upper", "true")
+			next.ServeHTTP(upperWriter{ResponseWriter: w}, r)
+		})
+	}
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	// 外层中间件能够观察到标准库中间件改写后的响应
+	var observed string
+	s := NewHTTPServer(ServerWithMiddleware(
+		func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				next(ctx)
+				observed = string(ctx.RespData)
+			}
+		},
+		WrapMiddleware(upper),
+		WrapMiddleware(auth),
+	))
+	s.GET("/user", func(ctx *Context) {
+		ctx.RespStatusCode = http.StatusAccepted
+		ctx.RespData = []byte("hello " + ctx.Req.Header.Get("Authorization"))
+	})
+
+	testCases := []struct {
+		name          string
+		authorization string
+		wantCode      int
+		wantBody      string
+	}{
+		{name: "pass through", authorization: "tom", wantCode: http.StatusAccepted, wantBody: "HELLO TOM"},
+		{name: "short circuit", wantCode: http.StatusUnauthorized, wantBody: "UNAUTHORIZED\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/user", nil)
+			request.Header.Set("Authorization", testCase.authorization)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, "true", recorder.Header().Get("X-Upper"))
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+			assert.Equal(t, testCase.wantBody, observed)
+		})
+	}
+}
+
+// TestToStdMiddleware 测试将 Middleware 包装为标准库风格的中间件
+func TestToStdMiddleware(t *testing.T) {
+	notFoundPage := func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			if ctx.RespStatusCode == http.StatusNotFound {
+				ctx.RespData = []byte("custom not found")
+			}
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+	handler := ToStdMiddleware(notFoundPage)(mux)
+
+	testCases := []struct {
+		name     string
+		target   string
+		wantCode int
+		wantBody string
+	}{
+		{name: "found", target: "/hello", wantCode: http.StatusOK, wantBody: "hello"},
+		{name: "not found", target: "/missing", wantCode: http.StatusNotFound, wantBody: "custom not found"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+		})
+	}
+}
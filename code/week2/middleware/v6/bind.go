@@ -0,0 +1,425 @@
+package web
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bindSources 支持的绑定来源 即结构体字段上的标签名 同一字段上有多个来源标签时 按此顺序取第一个有值的来源
+var bindSources = []string{"path", "query", "form", "header", "cookie"}
+
+// defaultMaxMemory 解析multipart表单时保存在内存中的最大字节数 与 http.Request.FormValue 相同
+const defaultMaxMemory = 32 << 20
+
+// BindError 绑定某个字段失败时的错误
+type BindError struct {
+	Field  string // Field 字段在结构体中的路径 嵌套结构体的字段以"."连接 例如 Page.Size
+	Source string // Source 值的来源 即标签名 例如 query
+	Key    string // Key 值在来源中的键 即标签值 例如 page
+	Value  string // Value 无法转换的原始值
+	Err    error  // Err 转换失败的原因
+}
+
+// Error 返回错误信息
+func (e *BindError) Error() string {
+	return fmt.Sprintf("web绑定错误: 字段 %s 的值 %s:%s=%q 无效: %v", e.Field, e.Source, e.Key, e.Value, e.Err)
+}
+
+// Unwrap 返回转换失败的原因
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors 绑定时所有字段的错误 Bind 不会在第一个字段出错时停止 而是收集所有字段的错误
+type BindErrors []*BindError
+
+// Error 返回所有错误信息 每个错误占一行
+func (e BindErrors) Error() string {
+	return joinErrors(e)
+}
+
+// Unwrap 返回所有错误 用于支持 errors.Is 与 errors.As
+func (e BindErrors) Unwrap() []error {
+	return unwrapErrors(e)
+}
+
+// Bind 根据结构体字段上的标签 从请求的各部分中取值并绑定到给定的结构体实例上 target必须为指向结构体的指针 例如:
+//
+//	type Req struct {
+//		ID      int64     `path:"id"`
+//		Page    int       `query:"page" default:"1"`
+//		Tags    []string  `query:"tag"`
+//		Tenant  string    `header:"X-Tenant"`
+//		Name    string    `form:"name"`
+//		Session *string   `cookie:"sid"`
+//		Since   time.Time `query:"since" time_format:"2006-01-02"`
+//		Filter  struct {
+//			Status string `query:"status"`
+//		}
+//	}
+//
+// 支持的标签:
+// 1. path query form header cookie 分别表示从路径参数 查询字符串 表单 请求头 cookie 中取值 form同时包含查询字符串中的值
+// 2. default 来源中没有值(或值为空字符串且字段不是字符串类型)且字段为零值时使用的默认值 切片类型的默认值以","分隔
+// 3. time_format time.Time 类型的格式 默认为 time.RFC3339
+// 支持的字段类型: 字符串 布尔 整数 浮点数 time.Time time.Duration 实现了 encoding.TextUnmarshaler 的类型 以及它们的指针和切片
+// 没有来源标签的结构体字段(包括内嵌结构体)会递归绑定 没有标签的其他字段保持不变
+// 请求体会先根据请求头 Content-Type 选择编解码器解码到结构体上 再根据标签绑定 因此标签中的值会覆盖请求体中的值 详见 bindBody
+// 某个字段绑定失败时不会停止绑定 而是收集所有字段的错误后返回 BindErrors
+// 所有字段绑定成功后 根据字段上的 validate 标签进行校验 校验失败时返回 ValidationErrors 详见 Validate
+func (c *Context) Bind(target any) error {
+	value := reflect.ValueOf(target)
+	if target == nil || value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("web绑定错误: 给定的实例必须为指向结构体的指针")
+	}
+
+	if err := c.bindBody(target); err != nil {
+		return err
+	}
+
+	if err := c.parseForm(); err != nil {
+		c.checkBodyTooLarge(err)
+		return err
+	}
+
+	var errs BindErrors
+	for _, field := range bindFieldsOf(value.Elem().Type()) {
+		if err := bindStructField(value.Elem(), field, c.bindValues); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	// 绑定成功后根据 validate 标签校验
+	return Validate(target)
+}
+
+// parseForm 解析表单 multipart表单同样会被解析
+func (c *Context) parseForm() error {
+	if c.Req.Form != nil {
+		return nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(c.Req.Header.Get("Content-Type"))
+	if contentType == "multipart/form-data" {
+		return c.Req.ParseMultipartForm(defaultMaxMemory)
+	}
+	return c.Req.ParseForm()
+}
+
+// bindStructField 通过lookup从给定来源中取值并绑定到给定字段上
+func bindStructField(root reflect.Value, field bindField, lookup func(source string, key string) []string) *BindError {
+	source, key, values := "", "", []string(nil)
+	for _, tag := range field.tags {
+		source, key = tag.source, tag.key
+		values = lookup(tag.source, tag.key)
+		if !isEmptyValues(values, field.typ) {
+			break
+		}
+	}
+
+	if isEmptyValues(values, field.typ) {
+		// 字段已有值(例如来自请求体)时不使用默认值
+		if !field.hasDefault || !isZeroField(root, field.index) {
+			return nil
+		}
+		values = field.defaults
+	}
+
+	// Tips: 仅在确定需要赋值时才分配嵌套的指针结构体 否则没有任何值的嵌套指针结构体依旧为nil
+	target := fieldByIndex(root, field.index)
+	if err := setValues(target, values, field.timeFormat); err != nil {
+		return &BindError{
+			Field:  field.name,
+			Source: source,
+			Key:    key,
+			Value:  strings.Join(values, ","),
+			Err:    err,
+		}
+	}
+	return nil
+}
+
+// bindValues 从请求的给定来源中取出给定键的所有值
+func (c *Context) bindValues(source string, key string) []string {
+	switch source {
+	case "path":
+		if value, ok := c.PathParams.Get(key); ok {
+			return []string{value}
+		}
+	case "query":
+		if c.queryValues == nil {
+			c.queryValues = c.Req.URL.Query()
+		}
+		return c.queryValues[key]
+	case "form":
+		return c.Req.Form[key]
+	case "header":
+		return c.Req.Header.Values(key)
+	case "cookie":
+		if cookie, err := c.Req.Cookie(key); err == nil {
+			return []string{cookie.Value}
+		}
+	}
+	return nil
+}
+
+// isEmptyValues 判断取出的值是否为空 非字符串类型的字段上 空字符串同样视为没有值
+func isEmptyValues(values []string, typ reflect.Type) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return typ.Kind() != reflect.String && len(values) == 1 && values[0] == ""
+}
+
+// isZeroField 判断按索引路径获取的嵌套字段是否为零值 途经nil指针时视为零值 且不会分配指针
+func isZeroField(v reflect.Value, index []int) bool {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return true
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v.IsZero()
+}
+
+// fieldByIndex 按索引路径获取嵌套字段 途经的nil指针会被分配
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v
+}
+
+// bindTag 字段上的来源标签
+type bindTag struct {
+	source string // source 来源 即标签名
+	key    string // key 来源中的键 即标签值
+}
+
+// bindField 需要绑定的字段 由结构体类型解析得到 并按结构体类型缓存
+type bindField struct {
+	name       string       // name 字段在结构体中的路径
+	index      []int        // index 字段的索引路径 用于 fieldByIndex
+	typ        reflect.Type // typ 字段的类型
+	tags       []bindTag    // tags 字段上的所有来源标签 按 bindSources 的顺序排列
+	defaults   []string     // defaults 默认值
+	hasDefault bool         // hasDefault 是否设置了默认值 用于区分默认值为空字符串与没有默认值
+	timeFormat string       // timeFormat time.Time 类型的格式
+}
+
+// hasSource 判断字段上是否有给定来源的标签
+func (f bindField) hasSource(source string) bool {
+	for _, tag := range f.tags {
+		if tag.source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// bindFieldsCache 结构体类型到需要绑定的字段的缓存 避免每次绑定都解析结构体标签
+var bindFieldsCache sync.Map
+
+// bindFieldsOf 解析给定结构体类型中所有需要绑定的字段
+func bindFieldsOf(typ reflect.Type) []bindField {
+	if fields, ok := bindFieldsCache.Load(typ); ok {
+		return fields.([]bindField)
+	}
+
+	fields := collectBindFields(typ, nil, "", map[reflect.Type]bool{})
+	bindFieldsCache.Store(typ, fields)
+	return fields
+}
+
+// collectBindFields 递归收集结构体类型中需要绑定的字段
+// visiting 记录当前递归路径上的结构体类型 避免自引用的结构体无限递归
+func collectBindFields(typ reflect.Type, index []int, prefix string, visiting map[reflect.Type]bool) []bindField {
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	var res []bindField
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+		name := structField.Name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		var tags []bindTag
+		for _, source := range bindSources {
+			if key, ok := structField.Tag.Lookup(source); ok && key != "-" {
+				tags = append(tags, bindTag{source: source, key: key})
+			}
+		}
+
+		if len(tags) != 0 {
+			defaultValue, hasDefault := structField.Tag.Lookup("default")
+			field := bindField{
+				name:       name,
+				index:      fieldIndex,
+				typ:        structField.Type,
+				tags:       tags,
+				hasDefault: hasDefault,
+				timeFormat: structField.Tag.Get("time_format"),
+			}
+			if hasDefault {
+				field.defaults = []string{defaultValue}
+				if structField.Type.Kind() == reflect.Slice {
+					field.defaults = strings.Split(defaultValue, ",")
+				}
+			}
+			res = append(res, field)
+			continue
+		}
+
+		// 没有来源标签的结构体字段 递归绑定其中的字段
+		elemType := structField.Type
+		if elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() == reflect.Struct && !isScalarType(elemType) && !visiting[elemType] {
+			res = append(res, collectBindFields(elemType, fieldIndex, name, visiting)...)
+		}
+	}
+	return res
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isScalarType 判断给定类型是否由单个字符串转换得到 即不需要递归绑定的结构体类型
+func isScalarType(typ reflect.Type) bool {
+	return typ == timeType || reflect.PointerTo(typ).Implements(textUnmarshalerType)
+}
+
+// setValues 将字符串形式的值转换后赋给给定的字段 切片类型的字段使用所有的值 其他类型的字段使用第1个值
+func setValues(v reflect.Value, values []string, timeFormat string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setValues(elem.Elem(), values, timeFormat); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice && !v.Addr().Type().Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value, timeFormat); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return setValue(v, values[0], timeFormat)
+}
+
+// setValue 将字符串形式的值转换后赋给给定的值
+func setValue(v reflect.Value, value string, timeFormat string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), value, timeFormat); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok && v.Type() != timeType {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch v.Type() {
+	case timeType:
+		if timeFormat == "" {
+			timeFormat = time.RFC3339
+		}
+		t, err := time.Parse(timeFormat, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", v.Type())
+	}
+	return nil
+}
+
+// Bind 根据结构体字段上的标签绑定请求的各部分 详见 Context.Bind 该方法是线程安全的
+func (s *SafeContext) Bind(target any) error {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	return s.Context.Bind(target)
+}
@@ -0,0 +1,187 @@
+package web
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bindLevel 实现了 encoding.TextUnmarshaler 的类型
+type bindLevel int
+
+func (l *bindLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return assert.AnError
+	}
+	return nil
+}
+
+// BindPage 用于测试内嵌结构体的绑定
+type BindPage struct {
+	Page int `query:"page" default:"1"`
+	Size int `query:"size" default:"20"`
+}
+
+// bindRequest 用于测试绑定的请求结构体
+type bindRequest struct {
+	ID       int64         `path:"id"`
+	Tags     []string      `query:"tag"`
+	IDs      []uint        `query:"ids" default:"1,2"`
+	Ratio    float64       `query:"ratio"`
+	Debug    bool          `query:"debug"`
+	Since    time.Time     `query:"since" time_format:"2006-01-02"`
+	Timeout  time.Duration `query:"timeout" default:"3s"`
+	Level    bindLevel     `query:"level"`
+	IP       net.IP        `header:"X-Real-IP"`
+	Tenant   string        `header:"X-Tenant"`
+	Name     *string       `form:"name"`
+	Nickname string        `form:"nickname" default:"anonymous"`
+	Session  string        `cookie:"sid"`
+	Trace    string        `header:"X-Trace" query:"trace"`
+	Ignored  string        `query:"-"`
+	Filter   struct {
+		Status string `query:"status"`
+		Owner  *struct {
+			Name string `query:"owner"`
+		}
+	}
+	BindPage
+	internal string
+}
+
+// TestContext_Bind 测试根据结构体标签绑定请求
+func TestContext_Bind(t *testing.T) {
+	s := NewHTTPServer()
+	var got bindRequest
+	var bindErr error
+	s.POST("/users/:id", func(ctx *Context) {
+		got = bindRequest{}
+		bindErr = ctx.Bind(&got)
+	})
+
+	serve := func(target string, body string, header map[string]string) {
+		request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for key, value := range header {
+			request.Header.Set(key, value)
+		}
+		s.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	serve("/users/12?tag=a&tag=b&ratio=0.5&debug=true&since=2024-01-02&level=high&status=open&owner=tom&size=50&trace=q&Ignored=x&page=",
+		"name=Tom", map[string]string{
+			"X-Real-IP": "10.0.0.1",
+			"X-Tenant":  "acme",
+			"X-Trace":   "h",
+			"Cookie":    "sid=abc",
+		})
+	require.NoError(t, bindErr)
+	name := "Tom"
+	assert.Equal(t, int64(12), got.ID)
+	assert.Equal(t, []string{"a", "b"}, got.Tags)
+	assert.Equal(t, []uint{1, 2}, got.IDs)
+	assert.Equal(t, 0.5, got.Ratio)
+	assert.True(t, got.Debug)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), got.Since)
+	assert.Equal(t, 3*time.Second, got.Timeout)
+	assert.Equal(t, bindLevel(2), got.Level)
+	assert.Equal(t, net.ParseIP("10.0.0.1"), got.IP)
+	assert.Equal(t, "acme", got.Tenant)
+	assert.Equal(t, &name, got.Name)
+	assert.Equal(t, "anonymous", got.Nickname)
+	assert.Equal(t, "abc", got.Session)
+	assert.Equal(t, "q", got.Trace)
+	assert.Equal(t, "", got.Ignored)
+	assert.Equal(t, "open", got.Filter.Status)
+	require.NotNil(t, got.Filter.Owner)
+	assert.Equal(t, "tom", got.Filter.Owner.Name)
+	assert.Equal(t, 1, got.Page)
+	assert.Equal(t, 50, got.Size)
+
+	// 没有值的嵌套指针结构体保持为nil 来源没有值时使用下一个来源
+	serve("/users/12", "", map[string]string{"X-Trace": "h"})
+	require.NoError(t, bindErr)
+	assert.Nil(t, got.Filter.Owner)
+	assert.Nil(t, got.Name)
+	assert.Equal(t, "h", got.Trace)
+
+	// 收集所有字段的错误
+	serve("/users/abc?ratio=x&debug=maybe&since=yesterday&level=mid&ids=1,2", "", nil)
+	var errs BindErrors
+	require.ErrorAs(t, bindErr, &errs)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{"ID", "IDs", "Ratio", "Debug", "Since", "Level"}, fields)
+	assert.Equal(t, "path", errs[0].Source)
+	assert.Equal(t, "id", errs[0].Key)
+	assert.Equal(t, "abc", errs[0].Value)
+	assert.ErrorIs(t, errs[5], assert.AnError)
+}
+
+// TestContext_Bind_multipart 测试绑定multipart表单
+func TestContext_Bind_multipart(t *testing.T) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("name", "Tom"))
+	require.NoError(t, writer.WriteField("age", "18"))
+	require.NoError(t, writer.Close())
+
+	request := httptest.NewRequest(http.MethodPost, "/", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx := &Context{Req: request}
+
+	var got struct {
+		Name string `form:"name"`
+		Age  uint8  `form:"age"`
+	}
+	require.NoError(t, ctx.Bind(&got))
+	assert.Equal(t, "Tom", got.Name)
+	assert.Equal(t, uint8(18), got.Age)
+}
+
+// TestContext_Bind_Illegal_Case 测试绑定到非法的实例
+func TestContext_Bind_Illegal_Case(t *testing.T) {
+	ctx := &Context{Req: httptest.NewRequest(http.MethodGet, "/", nil)}
+	var value struct {
+		Page int      `query:"page"`
+		Ch   chan int `query:"ch"`
+	}
+
+	testCases := []struct {
+		name   string
+		target any
+	}{
+		{name: "nil", target: nil},
+		{name: "not pointer", target: value},
+		{name: "nil pointer", target: (*bindRequest)(nil)},
+		{name: "pointer to map", target: &map[string]string{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Error(t, ctx.Bind(testCase.target))
+		})
+	}
+
+	// 不支持的字段类型
+	ctx = &Context{Req: httptest.NewRequest(http.MethodGet, "/?page=1&ch=1", nil)}
+	var errs BindErrors
+	require.ErrorAs(t, ctx.Bind(&value), &errs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Ch", errs[0].Field)
+	assert.Equal(t, 1, value.Page)
+}
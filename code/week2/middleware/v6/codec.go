@@ -0,0 +1,370 @@
+package web
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnsupportedMediaType 请求头 Content-Type 没有对应的编解码器
+	ErrUnsupportedMediaType = errors.New("web绑定错误: 不支持的Content-Type")
+	// ErrNotAcceptable 请求头 Accept 中没有可用的编解码器
+	ErrNotAcceptable = errors.New("web: 没有可接受的响应格式")
+)
+
+// Codec 编解码器 用于根据请求头 Content-Type 解码请求体 以及根据请求头 Accept 编码响应体
+// 内置了 JSON XML 表单(application/x-www-form-urlencoded) 纯文本 四种编解码器
+// 其他格式(例如msgpack protobuf)可以实现本接口后通过 RegisterCodec 注册
+type Codec interface {
+	// ContentType 返回编解码器对应的媒体类型 同时作为响应头 Content-Type 可以包含参数 例如 text/plain; charset=utf-8
+	ContentType() string
+	// Encode 将给定的实例编码为响应体
+	Encode(obj any) ([]byte, error)
+	// Decode 将请求体解码到给定的实例上
+	Decode(data []byte, target any) error
+}
+
+// registeredCodec 已注册的编解码器
+type registeredCodec struct {
+	mediaType string // mediaType 不含参数的小写媒体类型 例如 text/plain
+	codec     Codec  // codec 编解码器
+}
+
+// codecsMutex 保护codecs
+var codecsMutex sync.RWMutex
+
+// codecs 所有已注册的编解码器 按注册顺序排列
+// 请求头 Accept 为空 或多个编解码器的权重与位置均相同时 优先使用先注册的编解码器
+var codecs = []registeredCodec{
+	{mediaType: "application/json", codec: jsonCodec{}},
+	{mediaType: "application/xml", codec: xmlCodec{}},
+	{mediaType: "application/x-www-form-urlencoded", codec: formCodec{}},
+	{mediaType: "text/plain", codec: textCodec{}},
+}
+
+// RegisterCodec 注册编解码器 媒体类型相同的编解码器会被替换 例如:
+//
+//	type msgpackCodec struct{}
+//
+//	func (msgpackCodec) ContentType() string                  { return "application/msgpack" }
+//	func (msgpackCodec) Encode(obj any) ([]byte, error)       { return msgpack.Marshal(obj) }
+//	func (msgpackCodec) Decode(data []byte, target any) error { return msgpack.Unmarshal(data, target) }
+//
+//	RegisterCodec(msgpackCodec{})
+//
+// Tips: ContentType 返回非法的媒体类型时panic
+func RegisterCodec(codec Codec) {
+	mediaType, _, err := mime.ParseMediaType(codec.ContentType())
+	if err != nil {
+		panic(fmt.Sprintf("web: 非法编解码器,媒体类型 %s 不合规: %v", codec.ContentType(), err))
+	}
+
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	for i, registered := range codecs {
+		if registered.mediaType == mediaType {
+			codecs[i].codec = codec
+			return
+		}
+	}
+	codecs = append(codecs, registeredCodec{mediaType: mediaType, codec: codec})
+}
+
+// codecFor 查找给定媒体类型的编解码器
+// 没有精确匹配时 带结构化后缀的媒体类型使用后缀对应的编解码器 例如 application/problem+json 使用 application/json
+func codecFor(mediaType string) (Codec, bool) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	candidates := []string{mediaType}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		candidates = append(candidates, "application/"+mediaType[i+1:])
+	}
+	for _, candidate := range candidates {
+		for _, registered := range codecs {
+			if registered.mediaType == candidate {
+				return registered.codec, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// bindBody 根据请求头 Content-Type 选择编解码器 将请求体解码到给定的实例上
+// 没有请求体 请求体为空 或没有 Content-Type 时不做解码
+// 表单与multipart表单不经过编解码器 而是通过 form 标签绑定
+func (c *Context) bindBody(target any) error {
+	contentType := c.Req.Header.Get("Content-Type")
+	if c.Req.Body == nil || c.Req.Body == http.NoBody || contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w %s", ErrUnsupportedMediaType, contentType)
+	}
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data" {
+		return nil
+	}
+
+	codec, ok := codecFor(mediaType)
+	if !ok {
+		return fmt.Errorf("%w %s", ErrUnsupportedMediaType, mediaType)
+	}
+
+	data, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		c.checkBodyTooLarge(err)
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err = codec.Decode(data, target); err != nil {
+		return fmt.Errorf("web绑定错误: 以 %s 格式解码请求体失败: %w", mediaType, err)
+	}
+	return nil
+}
+
+// Negotiate 根据请求头 Accept 选择编解码器 以对应的格式输出响应 例如:
+//
+//	// Accept: application/xml;q=0.9, application/json 时输出JSON
+//	// Accept: text/plain 时输出纯文本
+//	_ = ctx.Negotiate(http.StatusOK, user)
+//
+// 按权重(q值)从高到低选择 权重相同时优先选择在 Accept 中靠前的格式 Accept 为空时使用最先注册的编解码器(默认为JSON)
+// 没有可接受的格式时响应406并返回 ErrNotAcceptable 编码失败时返回错误 不修改响应
+func (c *Context) Negotiate(status int, obj any) error {
+	c.Resp.Header().Add("Vary", "Accept")
+
+	codec, ok := negotiateCodec(c.Req.Header.Get("Accept"))
+	if !ok {
+		c.RespStatusCode = http.StatusNotAcceptable
+		c.RespData = []byte("Not Acceptable")
+		return ErrNotAcceptable
+	}
+
+	data, err := codec.Encode(obj)
+	if err != nil {
+		return err
+	}
+
+	c.Resp.Header().Set("Content-Type", codec.ContentType())
+	c.Resp.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	c.RespStatusCode = status
+	c.RespData = data
+	return nil
+}
+
+// negotiateCodec 根据请求头 Accept 选择编解码器 规则详见 Context.Negotiate
+func negotiateCodec(accept string) (Codec, bool) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	if strings.TrimSpace(accept) == "" {
+		if len(codecs) == 0 {
+			return nil, false
+		}
+		return codecs[0].codec, true
+	}
+
+	ranges := parseQualityList(accept)
+	var best Codec
+	bestQ, bestPos := 0.0, 0
+	for _, registered := range codecs {
+		q, pos, ok := acceptQuality(ranges, registered.mediaType)
+		if !ok || q <= 0 {
+			continue
+		}
+		if best == nil || q > bestQ || q == bestQ && pos < bestPos {
+			best, bestQ, bestPos = registered.codec, q, pos
+		}
+	}
+	return best, best != nil
+}
+
+// acceptQuality 返回给定媒体类型在 Accept 中的权重与匹配的媒体范围的位置
+// 多个媒体范围均匹配时 以最精确的为准 即 type/subtype 优先于 type/* 优先于 */*
+func acceptQuality(ranges []qualityValue, mediaType string) (q float64, pos int, ok bool) {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	specificity := -1
+	for i, r := range ranges {
+		s := -1
+		switch r.value {
+		case mediaType:
+			s = 2
+		case mainType + "/*":
+			s = 1
+		case "*/*", "*":
+			s = 0
+		}
+		if s > specificity {
+			specificity, q, pos = s, r.q, i
+		}
+	}
+	return q, pos, specificity >= 0
+}
+
+// qualityValue 带权重的请求头的值 例如 Accept Accept-Language
+type qualityValue struct {
+	value string  // value 小写的值 不含参数
+	q     float64 // q 权重 默认为1
+}
+
+// parseQualityList 解析以","分隔的带权重的请求头 结果按请求头中的顺序排列 包括权重为0的值
+func parseQualityList(header string) []qualityValue {
+	var res []qualityValue
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			name, v, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(name) != "q" {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = parsed
+			}
+		}
+		res = append(res, qualityValue{value: value, q: q})
+	}
+	return res
+}
+
+// jsonCodec JSON编解码器
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                  { return "application/json" }
+func (jsonCodec) Encode(obj any) ([]byte, error)       { return json.Marshal(obj) }
+func (jsonCodec) Decode(data []byte, target any) error { return json.Unmarshal(data, target) }
+
+// xmlCodec XML编解码器
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string                  { return "application/xml" }
+func (xmlCodec) Encode(obj any) ([]byte, error)       { return xml.Marshal(obj) }
+func (xmlCodec) Decode(data []byte, target any) error { return xml.Unmarshal(data, target) }
+
+// formCodec 表单编解码器
+// 编码支持 url.Values map[string][]string map[string]string
+// 解码支持指向以上类型的指针 以及指向结构体的指针 结构体根据 form 标签绑定 规则与 Context.Bind 相同
+type formCodec struct{}
+
+func (formCodec) ContentType() string { return "application/x-www-form-urlencoded" }
+
+func (formCodec) Encode(obj any) ([]byte, error) {
+	switch values := obj.(type) {
+	case url.Values:
+		return []byte(values.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(values).Encode()), nil
+	case map[string]string:
+		res := make(url.Values, len(values))
+		for key, value := range values {
+			res.Set(key, value)
+		}
+		return []byte(res.Encode()), nil
+	}
+	return nil, fmt.Errorf("web: 表单编解码器不支持编码类型 %T", obj)
+}
+
+func (formCodec) Decode(data []byte, target any) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	switch t := target.(type) {
+	case *url.Values:
+		*t = values
+		return nil
+	case *map[string][]string:
+		*t = values
+		return nil
+	case *map[string]string:
+		*t = make(map[string]string, len(values))
+		for key := range values {
+			(*t)[key] = values.Get(key)
+		}
+		return nil
+	}
+
+	value := reflect.ValueOf(target)
+	if target == nil || value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("web: 表单编解码器不支持解码到类型 %T", target)
+	}
+
+	lookup := func(source string, key string) []string {
+		if source != "form" {
+			return nil
+		}
+		return values[key]
+	}
+	var errs BindErrors
+	for _, field := range bindFieldsOf(value.Elem().Type()) {
+		if !field.hasSource("form") {
+			continue
+		}
+		if err := bindStructField(value.Elem(), field, lookup); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// textCodec 纯文本编解码器
+// 编码时 字符串 []byte encoding.TextMarshaler fmt.Stringer error 使用其文本形式 其他类型使用 fmt.Sprint 的结果
+// 解码支持指向字符串 []byte 的指针 以及实现了 encoding.TextUnmarshaler 的类型
+type textCodec struct{}
+
+func (textCodec) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (textCodec) Encode(obj any) ([]byte, error) {
+	switch v := obj.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case encoding.TextMarshaler:
+		return v.MarshalText()
+	case fmt.Stringer:
+		return []byte(v.String()), nil
+	case error:
+		return []byte(v.Error()), nil
+	}
+	return []byte(fmt.Sprint(obj)), nil
+}
+
+func (textCodec) Decode(data []byte, target any) error {
+	switch t := target.(type) {
+	case *string:
+		*t = string(data)
+		return nil
+	case *[]byte:
+		*t = append((*t)[:0], data...)
+		return nil
+	case encoding.TextUnmarshaler:
+		return t.UnmarshalText(data)
+	}
+	return fmt.Errorf("web: 纯文本编解码器不支持解码到类型 %T", target)
+}
@@ -0,0 +1,377 @@
+package web
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// codecUser 用于测试编解码的结构体
+type codecUser struct {
+	XMLName xml.Name `json:"-" xml:"user"`
+	ID      int64    `json:"id" xml:"id" path:"id"`
+	Name    string   `json:"name" xml:"name" form:"name" validate:"required"`
+	Age     int      `json:"age" xml:"age" form:"age" default:"18"`
+}
+
+// String 实现 fmt.Stringer 用于测试纯文本编码
+func (u codecUser) String() string {
+	return u.Name
+}
+
+// TestContext_Negotiate 测试根据请求头 Accept 选择响应格式
+func TestContext_Negotiate(t *testing.T) {
+	s := NewHTTPServer()
+	s.GET("/user", func(ctx *Context) {
+		err := ctx.Negotiate(http.StatusOK, codecUser{ID: 1, Name: "Tom", Age: 18})
+		if err != nil {
+			assert.ErrorIs(t, err, ErrNotAcceptable)
+		}
+	})
+	s.GET("/form", func(ctx *Context) {
+		require.NoError(t, ctx.Negotiate(http.StatusOK, url.Values{"name": {"Tom"}}))
+	})
+
+	testCases := []struct {
+		name            string
+		target          string
+		accept          string
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "no accept",
+			target:          "/user",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"id":1,"name":"Tom","age":18}`,
+		},
+		{
+			name:            "any",
+			target:          "/user",
+			accept:          "*/*",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"id":1,"name":"Tom","age":18}`,
+		},
+		{
+			name:            "xml",
+			target:          "/user",
+			accept:          "application/xml",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/xml",
+			wantBody:        `<user><id>1</id><name>Tom</name><age>18</age></user>`,
+		},
+		{
+			name:            "q value",
+			target:          "/user",
+			accept:          "application/json;q=0.5, text/plain;q=0.8",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Tom",
+		},
+		{
+			name:            "same q prefers header order",
+			target:          "/user",
+			accept:          "application/xml, application/json",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/xml",
+			wantBody:        `<user><id>1</id><name>Tom</name><age>18</age></user>`,
+		},
+		{
+			name:            "type wildcard",
+			target:          "/user",
+			accept:          "text/*",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Tom",
+		},
+		{
+			name:            "more specific range wins",
+			target:          "/user",
+			accept:          "application/json;q=0, */*;q=0.1",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/xml",
+			wantBody:        `<user><id>1</id><name>Tom</name><age>18</age></user>`,
+		},
+		{
+			name:            "form",
+			target:          "/form",
+			accept:          "application/x-www-form-urlencoded",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/x-www-form-urlencoded",
+			wantBody:        "name=Tom",
+		},
+		{
+			name:     "not acceptable",
+			target:   "/user",
+			accept:   "image/png, text/html",
+			wantCode: http.StatusNotAcceptable,
+			wantBody: "Not Acceptable",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			if testCase.accept != "" {
+				request.Header.Set("Accept", testCase.accept)
+			}
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+			assert.Equal(t, "Accept", recorder.Header().Get("Vary"))
+			if testCase.wantContentType != "" {
+				assert.Equal(t, testCase.wantContentType, recorder.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// TestContext_Negotiate_Middleware 测试中间件可以读取协商后的响应
+func TestContext_Negotiate_Middleware(t *testing.T) {
+	var seen string
+	s := NewHTTPServer(ServerWithMiddleware(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			seen = string(ctx.RespData)
+		}
+	}))
+	s.GET("/", func(ctx *Context) {
+		require.NoError(t, ctx.Negotiate(http.StatusCreated, "hello"))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", "text/plain")
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "hello", seen)
+	assert.Equal(t, "hello", recorder.Body.String())
+}
+
+// TestContext_Bind_body 测试根据请求头 Content-Type 解码请求体
+func TestContext_Bind_body(t *testing.T) {
+	s := NewHTTPServer()
+	var got codecUser
+	var bindErr error
+	s.POST("/users/:id", func(ctx *Context) {
+		got = codecUser{}
+		bindErr = ctx.Bind(&got)
+	})
+
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		want        codecUser
+		wantErr     error
+	}{
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"id":3,"name":"Tom","age":20}`,
+			want:        codecUser{ID: 12, Name: "Tom", Age: 20},
+		},
+		{
+			name:        "structured suffix",
+			contentType: "application/merge-patch+json",
+			body:        `{"name":"Tom","age":20}`,
+			want:        codecUser{ID: 12, Name: "Tom", Age: 20},
+		},
+		{
+			name:        "xml",
+			contentType: "application/xml",
+			body:        `<user><name>Tom</name><age>20</age></user>`,
+			want:        codecUser{XMLName: xml.Name{Local: "user"}, ID: 12, Name: "Tom", Age: 20},
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        `name=Tom`,
+			want:        codecUser{ID: 12, Name: "Tom", Age: 18},
+		},
+		{
+			name:        "empty body",
+			contentType: "application/json",
+			want:        codecUser{ID: 12, Age: 18},
+			wantErr:     ValidationErrors{},
+		},
+		{
+			name:        "unsupported media type",
+			contentType: "application/msgpack",
+			body:        "\x81",
+			wantErr:     ErrUnsupportedMediaType,
+		},
+		{
+			name:        "malformed",
+			contentType: "application/json",
+			body:        `{"name":`,
+			wantErr:     &json.SyntaxError{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/users/12", strings.NewReader(testCase.body))
+			request.Header.Set("Content-Type", testCase.contentType)
+			s.ServeHTTP(httptest.NewRecorder(), request)
+
+			switch wantErr := testCase.wantErr.(type) {
+			case nil:
+				require.NoError(t, bindErr)
+				assert.Equal(t, testCase.want, got)
+			case ValidationErrors:
+				assert.ErrorAs(t, bindErr, &wantErr)
+				assert.Equal(t, testCase.want, got)
+			case *json.SyntaxError:
+				assert.ErrorAs(t, bindErr, &wantErr)
+			default:
+				assert.ErrorIs(t, bindErr, testCase.wantErr)
+			}
+		})
+	}
+}
+
+// TestFormCodec 测试表单编解码器
+func TestFormCodec(t *testing.T) {
+	codec := formCodec{}
+
+	data, err := codec.Encode(map[string]string{"b": "2", "a": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "a=1&b=2", string(data))
+	_, err = codec.Encode(codecUser{})
+	assert.Error(t, err)
+
+	var values url.Values
+	require.NoError(t, codec.Decode([]byte("a=1&a=2"), &values))
+	assert.Equal(t, url.Values{"a": {"1", "2"}}, values)
+
+	var m map[string]string
+	require.NoError(t, codec.Decode([]byte("a=1&a=2&b=3"), &m))
+	assert.Equal(t, map[string]string{"a": "1", "b": "3"}, m)
+
+	var user codecUser
+	require.NoError(t, codec.Decode([]byte("name=Tom&id=3"), &user))
+	assert.Equal(t, codecUser{Name: "Tom", Age: 18}, user)
+
+	var errs BindErrors
+	require.ErrorAs(t, codec.Decode([]byte("age=x"), &user), &errs)
+	assert.Equal(t, "Age", errs[0].Field)
+
+	assert.Error(t, codec.Decode([]byte("a=1"), &[]string{}))
+}
+
+// TestTextCodec 测试纯文本编解码器
+func TestTextCodec(t *testing.T) {
+	codec := textCodec{}
+	testCases := []struct {
+		name string
+		obj  any
+		want string
+	}{
+		{name: "string", obj: "hello", want: "hello"},
+		{name: "bytes", obj: []byte("hello"), want: "hello"},
+		{name: "stringer", obj: codecUser{Name: "Tom"}, want: "Tom"},
+		{name: "error", obj: errors.New("boom"), want: "boom"},
+		{name: "other", obj: 42, want: "42"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			data, err := codec.Encode(testCase.obj)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.want, string(data))
+		})
+	}
+
+	var text string
+	require.NoError(t, codec.Decode([]byte("hello"), &text))
+	assert.Equal(t, "hello", text)
+	var level bindLevel
+	require.NoError(t, codec.Decode([]byte("high"), &level))
+	assert.Equal(t, bindLevel(2), level)
+	assert.Error(t, codec.Decode([]byte("hello"), &codecUser{}))
+}
+
+// testCodec 用于测试注册自定义编解码器
+type testCodec struct {
+	contentType string
+}
+
+func (c testCodec) ContentType() string { return c.contentType }
+
+func (c testCodec) Encode(obj any) ([]byte, error) {
+	return []byte("test:" + obj.(string)), nil
+}
+
+func (c testCodec) Decode(data []byte, target any) error {
+	*target.(*string) = strings.TrimPrefix(string(data), "test:")
+	return nil
+}
+
+// TestRegisterCodec 测试注册自定义编解码器
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec(testCodec{contentType: "application/x-test"})
+
+	codec, ok := codecFor("application/x-test")
+	require.True(t, ok)
+	var got string
+	require.NoError(t, codec.Decode([]byte("test:hello"), &got))
+	assert.Equal(t, "hello", got)
+
+	codec, ok = negotiateCodec("application/x-test")
+	require.True(t, ok)
+	data, err := codec.Encode("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "test:hello", string(data))
+
+	// 媒体类型相同的编解码器被替换 不影响注册顺序
+	RegisterCodec(testCodec{contentType: "Application/X-Test; version=2"})
+	codec, ok = codecFor("application/x-test")
+	require.True(t, ok)
+	assert.Equal(t, "Application/X-Test; version=2", codec.ContentType())
+	codec, _ = negotiateCodec("")
+	assert.Equal(t, "application/json", codec.ContentType())
+
+	assert.Panics(t, func() {
+		RegisterCodec(testCodec{contentType: "not a media type"})
+	})
+}
+
+// TestParseQualityList 测试解析带权重的请求头
+func TestParseQualityList(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+		want   []qualityValue
+	}{
+		{name: "empty", header: "", want: nil},
+		{name: "single", header: "Text/HTML", want: []qualityValue{{value: "text/html", q: 1}}},
+		{
+			name:   "q values",
+			header: "text/html;level=1;q=0.5, application/json ; q=0, */*",
+			want: []qualityValue{
+				{value: "text/html", q: 0.5},
+				{value: "application/json", q: 0},
+				{value: "*/*", q: 1},
+			},
+		},
+		{name: "invalid q", header: "en;q=x, ,", want: []qualityValue{{value: "en", q: 1}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, parseQualityList(testCase.header))
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ParamValidator 路径参数校验函数 返回路由段是否满足约束
+type ParamValidator func(value string) bool
+
+// ParamConstraint 路径参数约束的构造函数 根据约束的参数创建校验函数
+// 例如 len(3,10) 的参数为 ["3", "10"] 无参数的约束(例如 int)的参数为nil
+type ParamConstraint func(args ...string) (ParamValidator, error)
+
+// constraintsMutex 保护constraints
+var constraintsMutex sync.RWMutex
+
+// constraints 约束名到约束构造函数的映射
+// 内置约束:
+// 1. int: 64位有符号整数
+// 2. uint: 64位无符号整数
+// 3. uuid: 形如 xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx 的UUID 不区分大小写
+// 4. alpha: 仅包含英文字母
+// 5. len(min,max): 字符数在[min, max]之间
+var constraints = map[string]ParamConstraint{
+	"int":   simpleConstraint(isInt),
+	"uint":  simpleConstraint(isUint),
+	"uuid":  simpleConstraint(isUUID),
+	"alpha": simpleConstraint(isAlpha),
+	"len":   lenConstraint,
+}
+
+// RegisterParamConstraint 注册自定义的路径参数约束 同名约束会被覆盖
+// 注册后即可在路由中使用 例如注册名为even的约束后 可以注册路由 /page/:no<even>
+// Tips: 约束在注册路由时解析 因此需要在注册路由之前注册约束
+func RegisterParamConstraint(name string, constraint ParamConstraint) {
+	constraintsMutex.Lock()
+	defer constraintsMutex.Unlock()
+	constraints[name] = constraint
+}
+
+// RegisterParamValidator 注册无参数的自定义路径参数约束
+func RegisterParamValidator(name string, validator ParamValidator) {
+	RegisterParamConstraint(name, simpleConstraint(validator))
+}
+
+// simpleConstraint 将校验函数包装为无参数的约束构造函数
+func simpleConstraint(validator ParamValidator) ParamConstraint {
+	return func(args ...string) (ParamValidator, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("web: 参数约束不接受参数 %v", args)
+		}
+		return validator, nil
+	}
+}
+
+// lenConstraint 创建校验字符数的校验函数
+func lenConstraint(args ...string) (ParamValidator, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("web: len约束需要2个参数 实际为 %v", args)
+	}
+
+	minLen, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	maxLen, err := strconv.Atoi(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if minLen < 0 || minLen > maxLen {
+		return nil, fmt.Errorf("web: len约束的参数非法 min = %d max = %d", minLen, maxLen)
+	}
+
+	return func(value string) bool {
+		count := utf8.RuneCountInString(value)
+		return count >= minLen && count <= maxLen
+	}, nil
+}
+
+// isInt 判断路由段是否为64位有符号整数
+func isInt(value string) bool {
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err == nil
+}
+
+// isUint 判断路由段是否为64位无符号整数
+func isUint(value string) bool {
+	_, err := strconv.ParseUint(value, 10, 64)
+	return err == nil
+}
+
+// isUUID 判断路由段是否为UUID
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if i == 8 || i == 13 || i == 18 || i == 23 {
+			if c != '-' {
+				return false
+			}
+			continue
+		}
+
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlpha 判断路由段是否仅包含英文字母
+func isAlpha(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isConstraintSegment 判断路由段是否为带约束的参数路由段
+// 判断依据:
+// 1. 以":"开头
+// 2. 包含"<"
+// 3. 以">"结尾
+func isConstraintSegment(segment string) bool {
+	return strings.HasPrefix(segment, ":") && strings.Contains(segment, "<") && strings.HasSuffix(segment, ">")
+}
+
+// parseConstraintSegment 解析带约束的参数路由段 返回参数名与校验函数
+// 例如 :name<len(3,10)> 的参数名为 name 约束名为 len 约束参数为 ["3", "10"]
+// 返回的错误只描述路由段的问题 由 node.childOrCreate 包装为 RouteError 的 Cause
+func parseConstraintSegment(segment string) (paramName string, validator ParamValidator, err error) {
+	start := strings.Index(segment, "<")
+	paramName = segment[1:start]
+	if paramName == "" {
+		return "", nil, errors.New("缺少参数名")
+	}
+
+	expr := segment[start+1 : len(segment)-1]
+	name, args := expr, []string(nil)
+	if open := strings.Index(expr, "("); open != -1 {
+		if !strings.HasSuffix(expr, ")") {
+			return "", nil, errors.New("参数约束格式错误")
+		}
+
+		name = expr[:open]
+		for _, arg := range strings.Split(expr[open+1:len(expr)-1], ",") {
+			args = append(args, strings.TrimSpace(arg))
+		}
+	}
+
+	constraintsMutex.RLock()
+	constraint, ok := constraints[name]
+	constraintsMutex.RUnlock()
+	if !ok {
+		return "", nil, fmt.Errorf("未知的参数约束 %s", name)
+	}
+
+	validator, err = constraint(args...)
+	if err != nil {
+		return "", nil, fmt.Errorf("参数约束 %s 创建失败: %w", name, err)
+	}
+	return paramName, validator, nil
+}
@@ -0,0 +1,220 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestParseConstraintSegment 测试解析带约束的参数路由段 以及内置约束的校验结果
+func TestParseConstraintSegment(t *testing.T) {
+	testCases := []struct {
+		name          string
+		segment       string
+		wantParamName string
+		valid         []string
+		invalid       []string
+	}{
+		{
+			name:          "int",
+			segment:       ":id<int>",
+			wantParamName: "id",
+			valid:         []string{"0", "123", "-12", "9223372036854775807"},
+			invalid:       []string{"", "abc", "1.5", "9223372036854775808"},
+		},
+		{
+			name:          "uint",
+			segment:       ":id<uint>",
+			wantParamName: "id",
+			valid:         []string{"0", "18446744073709551615"},
+			invalid:       []string{"-1", "abc", "18446744073709551616"},
+		},
+		{
+			name:          "uuid",
+			segment:       ":slug<uuid>",
+			wantParamName: "slug",
+			valid:         []string{"123e4567-e89b-12d3-a456-426614174000", "123E4567-E89B-12D3-A456-426614174000"},
+			invalid:       []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g", "abc"},
+		},
+		{
+			name:          "alpha",
+			segment:       ":name<alpha>",
+			wantParamName: "name",
+			valid:         []string{"tom", "Tom"},
+			invalid:       []string{"", "tom1", "汤姆"},
+		},
+		{
+			name:          "len",
+			segment:       ":name<len(3, 5)>",
+			wantParamName: "name",
+			valid:         []string{"tom", "jerry", "汤姆猫"},
+			invalid:       []string{"to", "tomcat"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			paramName, validator, err := parseConstraintSegment(testCase.segment)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.wantParamName, paramName)
+			for _, value := range testCase.valid {
+				assert.True(t, validator(value), value)
+			}
+			for _, value := range testCase.invalid {
+				assert.False(t, validator(value), value)
+			}
+		})
+	}
+}
+
+// TestParseConstraintSegment_error 测试解析非法的带约束参数路由段
+func TestParseConstraintSegment_error(t *testing.T) {
+	testCases := []struct {
+		name    string
+		segment string
+	}{
+		{name: "missing param name", segment: ":<int>"},
+		{name: "unknown constraint", segment: ":id<float>"},
+		{name: "unexpected args", segment: ":id<int(1)>"},
+		{name: "missing args", segment: ":id<len>"},
+		{name: "illegal args", segment: ":id<len(a,b)>"},
+		{name: "min greater than max", segment: ":id<len(5,3)>"},
+		{name: "unclosed args", segment: ":id<len(3,5>"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, _, err := parseConstraintSegment(testCase.segment)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestRouter_findRoute_constraint 测试带约束的参数路由的查找 约束不满足时尝试优先级更低的兄弟节点
+func TestRouter_findRoute_constraint(t *testing.T) {
+	RegisterParamValidator("lower", func(value string) bool {
+		return value != "" && strings.ToLower(value) == value
+	})
+
+	testRoutes := []TestNode{
+		{method: http.MethodGet, path: "/user/:id<int>"},
+		{method: http.MethodGet, path: "/user/:name"},
+		{method: http.MethodGet, path: "/user/me"},
+		{method: http.MethodGet, path: "/post/:slug<uuid>/comments"},
+		{method: http.MethodGet, path: "/post/:code(\\d{3})"},
+		{method: http.MethodGet, path: "/post/*"},
+		{method: http.MethodGet, path: "/tag/:tag<lower>"},
+	}
+
+	testCases := []struct {
+		name       string
+		path       string
+		isFound    bool
+		wantRoute  string
+		wantParams map[string]string
+	}{
+		{
+			name:      "static over constraint",
+			path:      "/user/me",
+			isFound:   true,
+			wantRoute: "user/me",
+		},
+		{
+			name:       "constraint over param",
+			path:       "/user/123",
+			isFound:    true,
+			wantRoute:  "user/:id<int>",
+			wantParams: map[string]string{"id": "123"},
+		},
+		{
+			name:       "constraint mismatch fallback to param",
+			path:       "/user/tom",
+			isFound:    true,
+			wantRoute:  "user/:name",
+			wantParams: map[string]string{"name": "tom"},
+		},
+		{
+			name:       "regex over constraint",
+			path:       "/post/123",
+			isFound:    true,
+			wantRoute:  "post/:code(\\d{3})",
+			wantParams: map[string]string{"code": "123"},
+		},
+		{
+			name:       "constraint child",
+			path:       "/post/123e4567-e89b-12d3-a456-426614174000/comments",
+			isFound:    true,
+			wantRoute:  "post/:slug<uuid>/comments",
+			wantParams: map[string]string{"slug": "123e4567-e89b-12d3-a456-426614174000"},
+		},
+		{
+			name:       "constraint mismatch fallback to wildcard",
+			path:       "/post/abc/comments",
+			isFound:    true,
+			wantRoute:  "post/*",
+			wantParams: map[string]string{"*": "abc/comments"},
+		},
+		{
+			name:       "custom validator",
+			path:       "/tag/golang",
+			isFound:    true,
+			wantRoute:  "tag/:tag<lower>",
+			wantParams: map[string]string{"tag": "golang"},
+		},
+		{
+			name:    "custom validator mismatch",
+			path:    "/tag/Golang",
+			isFound: false,
+		},
+	}
+
+	routers := map[string]router{
+		"map":   newRouter(),
+		"radix": newRadixRouter(),
+	}
+
+	mockHandleFunc := func(ctx *Context) {}
+	for name, r := range routers {
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
+
+		for _, testCase := range testCases {
+			t.Run(name+"/"+testCase.name, func(t *testing.T) {
+				foundNode, found := r.findRoute(http.MethodGet, testCase.path)
+				assert.Equal(t, testCase.isFound, found)
+				if !found {
+					return
+				}
+
+				assert.Equal(t, testCase.wantRoute, foundNode.node.route)
+				assert.Equal(t, testCase.wantParams, foundNode.pathParams)
+			})
+		}
+	}
+}
+
+// TestRouter_addRoute_constraint_Illegal_Case 测试注册非法的带约束参数路由
+func TestRouter_addRoute_constraint_Illegal_Case(t *testing.T) {
+	r := newRouter()
+	mockHandleFunc := func(ctx *Context) {}
+	r.addRoute(http.MethodGet, "/user/:id<int>", mockHandleFunc)
+
+	// 相同的带约束参数路由段共用同一个节点
+	assert.NotPanics(t, func() {
+		r.addRoute(http.MethodGet, "/user/:id<int>/detail", mockHandleFunc)
+	})
+
+	assert.Panicsf(t, func() {
+		r.addRoute(http.MethodGet, "/user/:id<uuid>", mockHandleFunc)
+	}, "web: 路由冲突,参数约束冲突.已存在路由 :id<int>")
+
+	assert.Panicsf(t, func() {
+		r.addRoute(http.MethodGet, "/user/:uid<int>", mockHandleFunc)
+	}, "web: 路由冲突,参数约束冲突.已存在路由 :id<int>")
+
+	assert.Panics(t, func() {
+		r.addRoute(http.MethodGet, "/order/:id<float>", mockHandleFunc)
+	})
+}
@@ -9,15 +9,41 @@ import (
 )
 
 // Context HandleFunc的上下文
+// Tips: Context 由对象池复用 请求处理完毕后会被重置 因此不能在 HandleFunc 返回后继续持有(例如在新的goroutine中使用)
 type Context struct {
 	Req            *http.Request       // Req 请求
 	Resp           http.ResponseWriter // Resp 响应
-	PathParams     map[string]string   // PathParams 路径参数名值对
+	PathParams     Params              // PathParams 路径参数名值对
+	HostParams     Params              // HostParams 主机参数名值对 详见 HTTPServer.Host
 	queryValues    url.Values          // queryValues 查询参数名值对
 	cookieSameSite http.SameSite       // cookieSameSite cookie的SameSite属性 即同源策略
 	MatchRoute     string              // MatchRoute 命中的路由
-	RespData       []byte              // RespData 响应数据 主要是给中间件使用
+	RespData       []byte              // RespData 响应数据 主要是给中间件使用 Negotiate Render 等方法同样通过 RespStatusCode 和 RespData 写出响应 中间件依旧可以读取和篡改
 	RespStatusCode int                 // RespStatusCode 响应状态码 主要是给中间件使用
+	server         *HTTPServer         // server 处理当前请求的HTTP服务器 用于根据路由名称生成URL
+}
+
+// reset 重置上下文 以便放回对象池后复用
+func (c *Context) reset() {
+	c.Req = nil
+	c.Resp = nil
+	// Tips: 保留路径参数切片的容量 复用时无需重新分配
+	c.PathParams = c.PathParams[:0]
+	c.HostParams = c.HostParams[:0]
+	c.queryValues = nil
+	c.cookieSameSite = 0
+	c.MatchRoute = ""
+	c.RespData = nil
+	c.RespStatusCode = 0
+	c.server = nil
+}
+
+// URLFor 根据路由名称生成URL 可用于生成重定向的Location响应头 详见 HTTPServer.URLFor
+func (c *Context) URLFor(name string, params map[string]string, query url.Values) (string, error) {
+	if c.server == nil {
+		return "", errors.New("web: 上下文未关联HTTP服务器 无法生成URL")
+	}
+	return c.server.URLFor(name, params, query)
 }
 
 // SetCookie 设置响应头中的Set-Cookie字段
@@ -26,6 +52,7 @@ func (c *Context) SetCookie(cookie *http.Cookie) {
 }
 
 // BindJSON 绑定请求体中的JSON到给定的实例(这里的实例不一定是结构体实例,还有可能是个map)上
+// 给定的实例为结构体时 绑定成功后根据字段上的 validate 标签进行校验 校验失败时返回 ValidationErrors 详见 Validate
 func (c *Context) BindJSON(target any) error {
 	if target == nil {
 		return errors.New("web绑定错误: 给定的实例为空")
@@ -36,13 +63,29 @@ func (c *Context) BindJSON(target any) error {
 	}
 
 	decoder := json.NewDecoder(c.Req.Body)
-	return decoder.Decode(target)
+	err := decoder.Decode(target)
+	if err != nil {
+		c.checkBodyTooLarge(err)
+		return err
+	}
+	return Validate(target)
+}
+
+// checkBodyTooLarge 若读取请求体时的错误为请求体过大 则将响应设置为413
+func (c *Context) checkBodyTooLarge(err error) {
+	if err == nil || !isBodyTooLarge(err) {
+		return
+	}
+
+	c.RespStatusCode = http.StatusRequestEntityTooLarge
+	c.RespData = []byte("Request Entity Too Large")
 }
 
 // FormValue 获取表单中给定键的值
 func (c *Context) FormValue(key string) (stringValue StringValue) {
 	err := c.Req.ParseForm()
 	if err != nil {
+		c.checkBodyTooLarge(err)
 		return StringValue{err: err}
 	}
 
@@ -69,11 +112,11 @@ func (c *Context) QueryValue(key string) (stringValue StringValue) {
 
 // PathValue 获取路径参数中给定键的值
 func (c *Context) PathValue(key string) (stringValue StringValue) {
-	if c.PathParams == nil {
+	if len(c.PathParams) == 0 {
 		return StringValue{err: errors.New("web绑定错误: 无任何路径参数")}
 	}
 
-	value, ok := c.PathParams[key]
+	value, ok := c.PathParams.Get(key)
 	if !ok {
 		return StringValue{err: errors.New("web绑定错误: 路径参数中不存在键: " + key)}
 	}
@@ -81,6 +124,20 @@ func (c *Context) PathValue(key string) (stringValue StringValue) {
 	return StringValue{value: value}
 }
 
+// HostValue 获取主机参数中给定键的值 例如通过 :tenant.example.com 注册的路由 可以获取tenant的值
+func (c *Context) HostValue(key string) (stringValue StringValue) {
+	if len(c.HostParams) == 0 {
+		return StringValue{err: errors.New("web绑定错误: 无任何主机参数")}
+	}
+
+	value, ok := c.HostParams.Get(key)
+	if !ok {
+		return StringValue{err: errors.New("web绑定错误: 主机参数中不存在键: " + key)}
+	}
+
+	return StringValue{value: value}
+}
+
 // RespJSON 以JSON格式输出相应
 func (c *Context) RespJSON(status int, obj any) (err error) {
 	data, err := json.Marshal(obj)
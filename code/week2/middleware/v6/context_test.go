@@ -1,6 +1,10 @@
 package web
 
 import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"sync"
 	"testing"
 )
@@ -29,3 +33,28 @@ func TestContext_SafeContext(t *testing.T) {
 	s.GET("/user/:name", handleFunc)
 	_ = s.Start(":8091")
 }
+
+// TestContext_reset 测试上下文被重置后不再残留上一个请求的数据
+func TestContext_reset(t *testing.T) {
+	ctx := &Context{
+		Req:            httptest.NewRequest(http.MethodGet, "/user/1?name=Tom", nil),
+		Resp:           httptest.NewRecorder(),
+		PathParams:     Params{{Key: "id", Value: "1"}},
+		HostParams:     Params{{Key: "tenant", Value: "foo"}},
+		queryValues:    url.Values{"name": []string{"Tom"}},
+		cookieSameSite: http.SameSiteLaxMode,
+		MatchRoute:     "user/:id",
+		RespData:       []byte("hello"),
+		RespStatusCode: http.StatusOK,
+		server:         NewHTTPServer(),
+	}
+
+	ctx.reset()
+	assert.Empty(t, ctx.PathParams)
+	// 路径参数切片的容量被保留 以便复用
+	assert.Equal(t, 1, cap(ctx.PathParams))
+	assert.Empty(t, ctx.HostParams)
+	ctx.PathParams = nil
+	ctx.HostParams = nil
+	assert.Equal(t, &Context{}, ctx)
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidPattern 路由不合规 例如路由为空字符串 不以"/"开头 包含连续的"/" 正则表达式无法编译等
+	ErrInvalidPattern = errors.New("web: 非法路由")
+	// ErrRouteConflict 路由与已注册的路由冲突 例如重复注册路由 同一位置注册了不同的参数路由等
+	ErrRouteConflict = errors.New("web: 路由冲突")
+	// ErrUnsupportedMethod 框架不支持的HTTP动词
+	ErrUnsupportedMethod = errors.New("web: 不支持的HTTP动词")
+)
+
+// RouteError 注册路由失败时返回的错误
+// 可以通过 errors.Is 判断错误的类型 例如 errors.Is(err, ErrRouteConflict)
+// 通过 errors.As 获取出错的路由与与之冲突的已注册路由
+type RouteError struct {
+	Err      error  // Err 错误的类型 为 ErrInvalidPattern ErrRouteConflict 或 ErrUnsupportedMethod
+	Method   string // Method 注册路由时的HTTP动词
+	Pattern  string // Pattern 注册的路由
+	Existing string // Existing 与之冲突的已注册路由 仅当Err为 ErrRouteConflict 时有值
+	Msg      string // Msg 错误的详细描述
+	Cause    error  // Cause 导致路由不合规的底层错误 例如参数约束的构造函数返回的错误 没有时为nil
+}
+
+// Error 返回错误信息
+func (e *RouteError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Msg)
+	if e.Cause != nil {
+		sb.WriteString(": " + e.Cause.Error())
+	}
+	if e.Pattern != "" || e.Method != "" {
+		fmt.Fprintf(&sb, ".路由 [%s %s]", e.Method, e.Pattern)
+	}
+	if e.Existing != "" {
+		fmt.Fprintf(&sb, " 已存在路由 [%s]", e.Existing)
+	}
+	return sb.String()
+}
+
+// Unwrap 返回错误的类型与底层错误 用于支持 errors.Is 与 errors.As
+func (e *RouteError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+// newRouteError 创建路由错误 HTTP动词与路由由 router.register 补全
+func newRouteError(err error, existing string, format string, args ...any) *RouteError {
+	return &RouteError{
+		Err:      err,
+		Existing: existing,
+		Msg:      fmt.Sprintf(format, args...),
+	}
+}
+
+// RouteErrors 批量校验路由时发现的所有错误 详见 HTTPServer.ValidateRoutes
+type RouteErrors []*RouteError
+
+// Error 返回所有错误信息 每个错误占一行
+func (e RouteErrors) Error() string {
+	return joinErrors(e)
+}
+
+// Unwrap 返回所有错误 用于支持 errors.Is 与 errors.As
+func (e RouteErrors) Unwrap() []error {
+	return unwrapErrors(e)
+}
+
+// joinErrors 拼接多个错误的错误信息 每个错误占一行 用于实现 RouteErrors BindErrors 与 ValidationErrors 的 Error 方法
+func joinErrors[E error](errs []E) string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// unwrapErrors 将具体类型的错误切片转换为 []error 用于实现 RouteErrors BindErrors 与 ValidationErrors 的 Unwrap 方法
+func unwrapErrors[E error](errs []E) []error {
+	res := make([]error, 0, len(errs))
+	for _, err := range errs {
+		res = append(res, err)
+	}
+	return res
+}
+
+// RouteSpec 待校验的路由 详见 HTTPServer.ValidateRoutes
+type RouteSpec struct {
+	Method string // Method 路由的HTTP动词
+	Path   string // Path 路由
+}
+
+// ValidateRoutes 批量校验路由 一次性返回所有路由中的全部问题 而非遇到第一个问题就返回
+// 每个路由会与已注册的路由以及同一批次中排在它前面的路由进行冲突检测
+// 本方法不会注册任何路由 全部合规时返回nil 否则返回 RouteErrors
+// 例如根据配置文件注册路由前 可以先校验整份配置 再逐一调用 AddRoute 注册
+func (s *HTTPServer) ValidateRoutes(routes ...RouteSpec) error {
+	// 在当前路由森林的拷贝上试注册 不影响正在使用的路由森林
+	scratch := s.currentRouter().clone()
+	placeholder := func(ctx *Context) {}
+
+	var errs RouteErrors
+	for _, route := range routes {
+		if err := scratch.register(route.Method, route.Path, placeholder); err != nil {
+			errs = append(errs, err.(*RouteError))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
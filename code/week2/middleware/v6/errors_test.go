@@ -0,0 +1,147 @@
+package web
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHTTPServer_AddRoute 测试注册失败时返回错误而非panic
+func TestHTTPServer_AddRoute(t *testing.T) {
+	mockHandleFunc := func(ctx *Context) {}
+	s := NewHTTPServer()
+	s.GET("/", mockHandleFunc)
+	s.GET("/user/:id/detail", mockHandleFunc)
+	s.GET("/order/*", mockHandleFunc)
+	s.GET("/blog/:id(\\d+)", mockHandleFunc)
+	s.GET("/item/:id<int>", mockHandleFunc)
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		wantErr      error
+		wantExisting string
+	}{
+		{name: "empty", method: http.MethodGet, path: "", wantErr: ErrInvalidPattern},
+		{name: "missing leading slash", method: http.MethodGet, path: "login", wantErr: ErrInvalidPattern},
+		{name: "trailing slash", method: http.MethodGet, path: "/login/", wantErr: ErrInvalidPattern},
+		{name: "duplicate slashes", method: http.MethodGet, path: "/login//home", wantErr: ErrInvalidPattern},
+		{name: "regex without name", method: http.MethodGet, path: "/a/:(\\d+)", wantErr: ErrInvalidPattern},
+		{name: "illegal regex", method: http.MethodGet, path: "/a/:id([a-z)", wantErr: ErrInvalidPattern},
+		{name: "unknown constraint", method: http.MethodGet, path: "/a/:id<float>", wantErr: ErrInvalidPattern},
+		{name: "unsupported method", method: "FETCH", path: "/a", wantErr: ErrUnsupportedMethod},
+		{name: "duplicate root", method: http.MethodGet, path: "/", wantErr: ErrRouteConflict, wantExisting: "/"},
+		{name: "duplicate route", method: http.MethodGet, path: "/user/:id/detail", wantErr: ErrRouteConflict, wantExisting: "/user/:id/detail"},
+		{name: "param conflict", method: http.MethodGet, path: "/user/:name", wantErr: ErrRouteConflict, wantExisting: "/user/:id/detail"},
+		{name: "wildcard over param", method: http.MethodGet, path: "/user/*", wantErr: ErrRouteConflict, wantExisting: "/user/:id/detail"},
+		{name: "param over wildcard", method: http.MethodGet, path: "/order/:id", wantErr: ErrRouteConflict, wantExisting: "/order/*"},
+		{name: "regex conflict", method: http.MethodGet, path: "/blog/:id([a-z]+)", wantErr: ErrRouteConflict, wantExisting: "/blog/:id(\\d+)"},
+		{name: "constraint conflict", method: http.MethodGet, path: "/item/:id<uuid>", wantErr: ErrRouteConflict, wantExisting: "/item/:id<int>"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			route, err := s.AddRoute(testCase.method, testCase.path, mockHandleFunc)
+			assert.Nil(t, route)
+			assert.ErrorIs(t, err, testCase.wantErr)
+
+			var routeErr *RouteError
+			require.ErrorAs(t, err, &routeErr)
+			assert.Equal(t, testCase.method, routeErr.Method)
+			assert.Equal(t, testCase.path, routeErr.Pattern)
+			assert.Equal(t, testCase.wantExisting, routeErr.Existing)
+		})
+	}
+
+	// 注册失败时不会残留本次注册过程中创建的节点
+	_, ok := s.trees[http.MethodGet].segmentChild("a")
+	assert.False(t, ok)
+	_, ok = s.trees[http.MethodPost]
+	assert.False(t, ok)
+	_, err := s.AddRoute(http.MethodPost, "/user/:id/detail/:x<float>", mockHandleFunc)
+	assert.ErrorIs(t, err, ErrInvalidPattern)
+	_, ok = s.trees[http.MethodPost]
+	assert.False(t, ok)
+
+	// 注册成功
+	route, err := s.Group("/api").AddRoute(http.MethodPost, "/users", func(ctx *Context) {
+		ctx.RespData = []byte("created")
+	})
+	require.NoError(t, err)
+	route.Name("create user")
+	u, err := s.URLFor("create user", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/users", u)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users", nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, "created", recorder.Body.String())
+
+	// panic版本的注册方法panic的值同样是 *RouteError
+	assert.PanicsWithError(t, "web: 路由冲突,参数路由冲突.路由 [GET /user/:name] 已存在路由 [/user/:id/detail]", func() {
+		s.GET("/user/:name", mockHandleFunc)
+	})
+}
+
+// TestHTTPServer_AddRoute_constraintCause 测试参数约束不合法时 RouteError 保留底层错误
+func TestHTTPServer_AddRoute_constraintCause(t *testing.T) {
+	errTestRange := errors.New("test: 区间非法")
+	RegisterParamConstraint("test_range", func(args ...string) (ParamValidator, error) {
+		return nil, errTestRange
+	})
+	s := NewHTTPServer()
+
+	_, err := s.AddRoute(http.MethodGet, "/item/:id<test_range(5,1)>", func(ctx *Context) {})
+	assert.ErrorIs(t, err, ErrInvalidPattern)
+	assert.ErrorIs(t, err, errTestRange)
+	assert.EqualError(t, err, "web: 非法路由,参数约束不合法.路由段 :id<test_range(5,1)>: "+
+		"参数约束 test_range 创建失败: test: 区间非法.路由 [GET /item/:id<test_range(5,1)>]")
+
+	var routeErr *RouteError
+	require.ErrorAs(t, err, &routeErr)
+	assert.Equal(t, "/item/:id<test_range(5,1)>", routeErr.Pattern)
+
+	// 未知的约束同样作为底层错误保留
+	_, err = s.AddRoute(http.MethodGet, "/item/:id<float>", func(ctx *Context) {})
+	assert.EqualError(t, err, "web: 非法路由,参数约束不合法.路由段 :id<float>: 未知的参数约束 float.路由 [GET /item/:id<float>]")
+}
+
+// TestHTTPServer_ValidateRoutes 测试批量校验路由
+func TestHTTPServer_ValidateRoutes(t *testing.T) {
+	s := NewHTTPServer()
+	s.GET("/user/:id", func(ctx *Context) {})
+
+	assert.NoError(t, s.ValidateRoutes(
+		RouteSpec{Method: http.MethodGet, Path: "/user/:id/detail"},
+		RouteSpec{Method: http.MethodPost, Path: "/user/:name"},
+	))
+
+	err := s.ValidateRoutes(
+		RouteSpec{Method: http.MethodGet, Path: "/user/:name"},
+		RouteSpec{Method: http.MethodGet, Path: "/order/detail"},
+		RouteSpec{Method: http.MethodGet, Path: "/order/detail/"},
+		RouteSpec{Method: http.MethodGet, Path: "/order/detail"},
+		RouteSpec{Method: "FETCH", Path: "/order"},
+	)
+	var errs RouteErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 4)
+	assert.ErrorIs(t, errs[0], ErrRouteConflict)
+	assert.Equal(t, "/user/:id", errs[0].Existing)
+	assert.ErrorIs(t, errs[1], ErrInvalidPattern)
+	assert.ErrorIs(t, errs[2], ErrRouteConflict)
+	assert.Equal(t, "/order/detail", errs[2].Existing)
+	assert.ErrorIs(t, errs[3], ErrUnsupportedMethod)
+	assert.True(t, errors.Is(err, ErrInvalidPattern))
+
+	// 校验不会注册任何路由
+	_, ok := s.trees[http.MethodGet].segmentChild("order")
+	assert.False(t, ok)
+	_, ok = s.trees[http.MethodPost]
+	assert.False(t, ok)
+}
@@ -0,0 +1,180 @@
+package web
+
+import "net/http"
+
+// Group 路由分组 同一分组下的路由共享路由前缀和中间件
+// 例如:
+//
+//	v1 := s.Group("/api/v1", auth)
+//	v1.GET("/users", handleFunc)      // 等价于注册 /api/v1/users 并为其添加auth中间件
+//	users := v1.Group("/users", limit)
+//	users.GET("/:id", handleFunc)     // 等价于注册 /api/v1/users/:id 并为其添加auth和limit中间件
+type Group struct {
+	prefix      string       // prefix 分组的路由前缀 嵌套分组的前缀为所有父分组前缀的拼接
+	middlewares []Middleware // middlewares 分组的中间件 嵌套分组的中间件为父分组中间件之后追加自身的中间件
+	server      *HTTPServer  // server 分组所属的HTTP服务器
+	host        *hostRouter  // host 分组绑定的主机模式 为nil时表示注册到默认路由森林 详见 HTTPServer.Host
+	err         *RouteError  // err 分组前缀不合规时的错误 在分组下注册路由时返回 嵌套分组继承父分组的错误
+}
+
+// Group 创建一个路由分组
+// 前缀必须以"/"开头 且除"/"外不能以"/"结尾 例如 /api/v1
+// 前缀不合规时不会立即panic 而是在分组下注册路由时返回 ErrInvalidPattern(AddRoute) 或panic(GET等方法)
+func (s *HTTPServer) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{
+		prefix:      prefix,
+		middlewares: middlewares,
+		server:      s,
+		err:         validatePrefix(prefix),
+	}
+}
+
+// Group 在当前分组下创建一个嵌套的路由分组
+func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	// Tips: 此处必须创建新的切片 否则多个子分组追加中间件时可能会共用父分组切片的底层数组
+	mdls := make([]Middleware, 0, len(g.middlewares)+len(middlewares))
+	mdls = append(mdls, g.middlewares...)
+	mdls = append(mdls, middlewares...)
+
+	fullPrefix, err := g.joinPath(prefix)
+	if err == nil {
+		err = validatePrefix(prefix)
+	}
+	if err != nil {
+		// 前缀不合规时仅用于在错误信息中展示注册的路由
+		fullPrefix = g.prefix + prefix
+	}
+
+	return &Group{
+		prefix:      fullPrefix,
+		middlewares: mdls,
+		server:      g.server,
+		host:        g.host,
+		err:         err,
+	}
+}
+
+// GET 在分组下注册GET请求路由
+func (g *Group) GET(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodGet, path, handleFunc)
+}
+
+// POST 在分组下注册POST请求路由
+func (g *Group) POST(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodPost, path, handleFunc)
+}
+
+// PUT 在分组下注册PUT请求路由
+func (g *Group) PUT(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodPut, path, handleFunc)
+}
+
+// PATCH 在分组下注册PATCH请求路由
+func (g *Group) PATCH(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodPatch, path, handleFunc)
+}
+
+// DELETE 在分组下注册DELETE请求路由
+func (g *Group) DELETE(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodDelete, path, handleFunc)
+}
+
+// HEAD 在分组下注册HEAD请求路由
+func (g *Group) HEAD(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodHead, path, handleFunc)
+}
+
+// OPTIONS 在分组下注册OPTIONS请求路由
+func (g *Group) OPTIONS(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodOptions, path, handleFunc)
+}
+
+// CONNECT 在分组下注册CONNECT请求路由
+func (g *Group) CONNECT(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodConnect, path, handleFunc)
+}
+
+// TRACE 在分组下注册TRACE请求路由
+func (g *Group) TRACE(path string, handleFunc HandleFunc) *Route {
+	return g.addRoute(http.MethodTrace, path, handleFunc)
+}
+
+// Any 在分组下为所有HTTP动词注册同一个路由
+func (g *Group) Any(path string, handleFunc HandleFunc) *Route {
+	var route *Route
+	for _, method := range httpMethods {
+		route = g.addRoute(method, path, handleFunc)
+	}
+	return g.newRoute(http.MethodGet, route.path)
+}
+
+// AddRoute 在分组下注册路由 注册失败时不会panic 而是返回 *RouteError 详见 HTTPServer.AddRoute
+// 分组前缀不合规 或给定的路由不以"/"开头时 返回的错误为 ErrInvalidPattern
+func (g *Group) AddRoute(method string, path string, handleFunc HandleFunc) (*Route, error) {
+	fullPath, err := g.joinPath(path)
+	if err != nil {
+		err.Method = method
+		return nil, err
+	}
+	if err := g.server.register(g.host, method, fullPath, handleFunc, g.middlewares...); err != nil {
+		return nil, err
+	}
+	return g.newRoute(method, fullPath), nil
+}
+
+// addRoute 将分组前缀与给定路由拼接后注册到HTTP服务器上 并为该路由添加分组的中间件 注册失败时panic
+func (g *Group) addRoute(method string, path string, handleFunc HandleFunc) *Route {
+	route, err := g.AddRoute(method, path, handleFunc)
+	if err != nil {
+		panic(err)
+	}
+	return route
+}
+
+// newRoute 创建一个在分组下已注册路由的 Route
+func (g *Group) newRoute(method string, path string) *Route {
+	route := g.server.newRoute(method, path)
+	if g.host != nil {
+		route.host = g.host.pattern
+	}
+	return route
+}
+
+// joinPath 拼接分组前缀与路由 前缀或路由不合规时返回 *RouteError
+// 1. 若路由为"/" 则表示前缀本身
+// 2. 若前缀为"/" 则表示路由本身
+// 3. 其他情况直接拼接 拼接结果的其余规则(例如不能包含连续的"/")交给 register 校验
+// Tips: 必须拒绝不以"/"开头的路由 否则 /api 与 users 会拼接为合法的 /apiusers register无法发现
+func (g *Group) joinPath(path string) (string, *RouteError) {
+	if g.err != nil {
+		err := *g.err
+		err.Pattern = g.prefix + path
+		return "", &err
+	}
+
+	if path != "/" && (path == "" || path[0] != '/') {
+		return "", &RouteError{Err: ErrInvalidPattern, Pattern: path, Msg: "web: 非法路由,分组下的路由必须以 '/' 开头"}
+	}
+
+	if path == "/" {
+		return g.prefix, nil
+	}
+
+	if g.prefix == "/" {
+		return path, nil
+	}
+
+	return g.prefix + path, nil
+}
+
+// validatePrefix 检测分组前缀是否合规 前缀必须以"/"开头 且除"/"外不能以"/"结尾
+func validatePrefix(prefix string) *RouteError {
+	if prefix == "" || prefix[0] != '/' {
+		return &RouteError{Err: ErrInvalidPattern, Pattern: prefix, Msg: "web: 非法路由,分组前缀必须以 '/' 开头"}
+	}
+
+	if prefix != "/" && prefix[len(prefix)-1] == '/' {
+		return &RouteError{Err: ErrInvalidPattern, Pattern: prefix, Msg: "web: 非法路由,分组前缀不能以 '/' 结尾"}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGroup 测试路由分组的前缀拼接 嵌套与中间件
+func TestGroup(t *testing.T) {
+	// recordMiddleware 创建一个将自身名称记录到响应数据中的中间件
+	recordMiddleware := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				ctx.RespData = append(ctx.RespData, []byte(name+" ")...)
+				next(ctx)
+			}
+		}
+	}
+
+	mockHandleFunc := func(ctx *Context) {
+		ctx.RespData = append(ctx.RespData, []byte(ctx.MatchRoute)...)
+	}
+
+	s := NewHTTPServer()
+	s.Use("/api/*", recordMiddleware("route"))
+
+	v1 := s.Group("/api/v1", recordMiddleware("v1"))
+	v1.GET("/", mockHandleFunc)
+	v1.POST("/orders", mockHandleFunc)
+
+	users := v1.Group("/users", recordMiddleware("users"))
+	users.GET("/:id", mockHandleFunc)
+
+	// 兄弟分组的中间件不能互相影响
+	admin := v1.Group("/admin", recordMiddleware("admin"))
+	admin.GET("/dashboard", mockHandleFunc)
+
+	root := s.Group("/")
+	root.GET("/login", mockHandleFunc)
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		wantBody string
+	}{
+		{
+			name:     "group root",
+			method:   http.MethodGet,
+			path:     "/api/v1",
+			wantBody: "route v1 api/v1",
+		},
+		{
+			name:     "group post",
+			method:   http.MethodPost,
+			path:     "/api/v1/orders",
+			wantBody: "route v1 api/v1/orders",
+		},
+		{
+			name:     "nested group",
+			method:   http.MethodGet,
+			path:     "/api/v1/users/123",
+			wantBody: "route v1 users api/v1/users/:id",
+		},
+		{
+			name:     "sibling group",
+			method:   http.MethodGet,
+			path:     "/api/v1/admin/dashboard",
+			wantBody: "route v1 admin api/v1/admin/dashboard",
+		},
+		{
+			name:     "root group",
+			method:   http.MethodGet,
+			path:     "/login",
+			wantBody: "login",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.path, nil)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+		})
+	}
+}
+
+// TestGroup_Illegal_Case 测试分组前缀或分组下的路由不合规 以及拼接后的路由冲突
+func TestGroup_Illegal_Case(t *testing.T) {
+	s := NewHTTPServer()
+	mockHandleFunc := func(ctx *Context) {}
+
+	testCases := []struct {
+		name    string
+		group   *Group
+		path    string
+		wantErr error
+	}{
+		{
+			// 不拒绝时会拼接为 /apiusers
+			name:    "path without leading slash",
+			group:   s.Group("/api"),
+			path:    "users",
+			wantErr: ErrInvalidPattern,
+		},
+		{
+			name:    "empty path",
+			group:   s.Group("/api"),
+			path:    "",
+			wantErr: ErrInvalidPattern,
+		},
+		{
+			name:    "path without leading slash in root group",
+			group:   s.Group("/"),
+			path:    "users",
+			wantErr: ErrInvalidPattern,
+		},
+		{
+			name:    "prefix with trailing slash",
+			group:   s.Group("/api/"),
+			path:    "/users",
+			wantErr: ErrInvalidPattern,
+		},
+		{
+			name:    "prefix without leading slash",
+			group:   s.Group("api"),
+			path:    "/users",
+			wantErr: ErrInvalidPattern,
+		},
+		{
+			name:    "nested prefix without leading slash",
+			group:   s.Group("/api").Group("v1"),
+			path:    "/users",
+			wantErr: ErrInvalidPattern,
+		},
+		{
+			name:    "nested group of illegal group",
+			group:   s.Group("/api/").Group("/v1"),
+			path:    "/users",
+			wantErr: ErrInvalidPattern,
+		},
+		{
+			name:    "route conflict",
+			group:   s.Group("/api"),
+			path:    "/orders",
+			wantErr: ErrRouteConflict,
+		},
+	}
+
+	s.GET("/api/orders", mockHandleFunc)
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := testCase.group.AddRoute(http.MethodGet, testCase.path, mockHandleFunc)
+			assert.ErrorIs(t, err, testCase.wantErr)
+			assert.Panics(t, func() {
+				testCase.group.GET(testCase.path, mockHandleFunc)
+			})
+		})
+	}
+
+	// 注册失败时不会注册任何路由
+	assert.Len(t, s.Routes(), 1)
+}
@@ -0,0 +1,210 @@
+package web
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+// hostKind 主机模式的类型 同时也是主机模式的匹配优先级 值越小优先级越高
+type hostKind int
+
+const (
+	// hostKindExact 精确主机 例如 admin.example.com
+	hostKindExact hostKind = iota
+	// hostKindParam 带参数的主机 例如 :tenant.example.com
+	hostKindParam
+	// hostKindWildcard 通配符主机 例如 *.example.com
+	hostKindWildcard
+)
+
+// hostRouter 绑定到某个主机模式上的路由森林
+// 每个主机模式都有独立的路由树 但与默认路由森林共用同一棵中间件树
+type hostRouter struct {
+	pattern string   // pattern 主机模式 已转为小写
+	labels  []string // labels 主机模式按"."切割后的标签
+	kind    hostKind // kind 主机模式的类型
+	*router          // router 主机模式的路由森林
+}
+
+// Host 创建一个绑定到给定主机模式上的路由分组 通过该分组注册的路由仅当请求的主机匹配该模式时才会命中
+// 主机模式支持:
+// 1. 精确主机 例如 admin.example.com
+// 2. 带参数的主机 例如 :tenant.example.com 参数标签匹配1个标签 其值可通过 Context.HostValue 获取
+// 3. 通配符主机 例如 *.example.com 通配符只能作为第1个标签 匹配1个或多个标签
+// 匹配优先级: 精确主机 > 带参数的主机 > 通配符主机 同类型的主机模式按注册顺序匹配
+// 若请求的主机没有匹配任何主机模式 或匹配的主机模式上没有该路径的路由 则使用默认的路由森林(即直接在 HTTPServer 上注册的路由)
+// 主机模式不区分大小写 且忽略请求中的端口号
+// 例如:
+//
+//	admin := s.Host("admin.example.com")
+//	admin.GET("/users", handleFunc)
+//	tenant := s.Host(":tenant.example.com")
+//	tenant.GET("/", func(ctx *Context) { ctx.HostValue("tenant") })
+func (s *HTTPServer) Host(pattern string) *Group {
+	var host *hostRouter
+	s.updateRouter(func(r *router) {
+		host = r.hostRouterOrCreate(pattern)
+	})
+
+	return &Group{
+		prefix: "/",
+		server: s,
+		host:   host,
+	}
+}
+
+// hostRouterOf 查找给定主机模式对应的路由森林
+func (r *router) hostRouterOf(pattern string) (*hostRouter, bool) {
+	pattern = strings.ToLower(pattern)
+	for _, h := range r.hosts {
+		if h.pattern == pattern {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// hostRouterOrCreate 查找给定主机模式对应的路由森林 若不存在则创建
+func (r *router) hostRouterOrCreate(pattern string) *hostRouter {
+	if h, ok := r.hostRouterOf(pattern); ok {
+		return h
+	}
+
+	pattern = strings.ToLower(pattern)
+	labels, kind := parseHostPattern(pattern)
+	child := newRouter()
+	child.radix = r.radix
+
+	// 与默认路由森林共用同一棵中间件树 因此需要保证中间件树已被创建
+	if r.mdlRoot == nil {
+		r.mdlRoot = &node{
+			path: "/",
+		}
+	}
+	child.mdlRoot = r.mdlRoot
+
+	h := &hostRouter{
+		pattern: pattern,
+		labels:  labels,
+		kind:    kind,
+		router:  &child,
+	}
+	r.hosts = append(r.hosts, h)
+
+	// Tips: 稳定排序 保证同类型的主机模式按注册顺序匹配
+	slices.SortStableFunc(r.hosts, func(a, b *hostRouter) int {
+		return int(a.kind) - int(b.kind)
+	})
+	return h
+}
+
+// parseHostPattern 解析并校验主机模式 返回主机模式的标签与类型
+func parseHostPattern(pattern string) ([]string, hostKind) {
+	if pattern == "" {
+		panic("web: 主机模式不能为空字符串")
+	}
+
+	kind := hostKindExact
+	labels := strings.Split(pattern, ".")
+	for i, label := range labels {
+		switch {
+		case label == "":
+			panic(fmt.Sprintf("web: 非法主机模式,主机模式中不得包含空标签.主机模式 %s", pattern))
+		case label == "*":
+			if i != 0 {
+				panic(fmt.Sprintf("web: 非法主机模式,通配符只能作为第1个标签.主机模式 %s", pattern))
+			}
+			kind = hostKindWildcard
+		case strings.HasPrefix(label, ":"):
+			if label == ":" {
+				panic(fmt.Sprintf("web: 非法主机模式,参数标签缺少参数名.主机模式 %s", pattern))
+			}
+			kind = max(kind, hostKindParam)
+		}
+	}
+	return labels, kind
+}
+
+// match 判断给定主机是否匹配当前主机模式 匹配时将参数标签的值追加到params中
+// host需已转为小写且不含端口号
+func (h *hostRouter) match(host string, params *Params) bool {
+	labels := strings.Split(host, ".")
+
+	// 通配符匹配1个或多个标签 因此只需要比较通配符之后的标签
+	patternLabels := h.labels
+	if h.kind == hostKindWildcard {
+		patternLabels = h.labels[1:]
+		if len(labels) <= len(patternLabels) {
+			return false
+		}
+		labels = labels[len(labels)-len(patternLabels):]
+	}
+
+	if len(labels) != len(patternLabels) {
+		return false
+	}
+
+	mark := params.len()
+	for i, label := range patternLabels {
+		if strings.HasPrefix(label, ":") {
+			params.add(label[1:], labels[i])
+			continue
+		}
+
+		if label != labels[i] {
+			params.truncate(mark)
+			return false
+		}
+	}
+	return true
+}
+
+// routerOf 根据请求的主机与路径选择用于查找路由的路由森林
+// 按优先级依次尝试匹配请求主机的主机模式 若该主机模式上存在该路径的路由(任意HTTP动词) 则使用该主机模式的路由森林
+// 否则使用默认的路由森林
+// 主机参数会被写入params中
+func (r *router) routerOf(host string, path string, params *Params) *router {
+	if len(r.hosts) == 0 {
+		return r
+	}
+
+	host = strings.ToLower(stripHostPort(host))
+	for _, h := range r.hosts {
+		mark := params.len()
+		if !h.match(host, params) {
+			continue
+		}
+
+		if h.hasRoute(path) {
+			return h.router
+		}
+		params.truncate(mark)
+	}
+	return r
+}
+
+// hasRoute 判断路由森林中是否存在能够处理给定路径的路由(任意HTTP动词)
+// Tips: 此处不使用 allowedMethods 因为它会分配并排序结果 而本方法在每个请求上都会执行 找到任意一个路由即可返回
+func (r *router) hasRoute(path string) bool {
+	for method := range r.trees {
+		if _, ok := r.findHandleRoute(method, path, nil); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stripHostPort 去掉主机中的端口号 支持IPv6地址 例如 [::1]:8080
+func stripHostPort(host string) string {
+	if !strings.Contains(host, ":") {
+		return host
+	}
+
+	h, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+	return h
+}
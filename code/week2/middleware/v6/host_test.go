@@ -0,0 +1,193 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHTTPServer_Host 测试基于主机的路由
+func TestHTTPServer_Host(t *testing.T) {
+	respond := func(name string) HandleFunc {
+		return func(ctx *Context) {
+			ctx.RespData = []byte(name + " " + ctx.HostValue("tenant").value + " " + ctx.PathValue("id").value)
+		}
+	}
+
+	s := NewHTTPServer()
+	s.GET("/", respond("default"))
+	s.GET("/health", respond("health"))
+	s.Host("*.example.com").GET("/", respond("wildcard"))
+	s.Host(":tenant.example.com").GET("/", respond("tenant"))
+	s.Host(":tenant.example.com").Group("/users").GET("/:id", respond("tenant user"))
+	s.Host("Admin.Example.com").GET("/", respond("admin"))
+	s.Host("admin.example.com").POST("/users", respond("admin users"))
+
+	testCases := []struct {
+		name     string
+		method   string
+		host     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "exact host",
+			method:   http.MethodGet,
+			host:     "admin.example.com",
+			path:     "/",
+			wantCode: http.StatusOK,
+			wantBody: "admin  ",
+		},
+		{
+			name:     "exact host ignores case and port",
+			method:   http.MethodGet,
+			host:     "ADMIN.example.com:8080",
+			path:     "/",
+			wantCode: http.StatusOK,
+			wantBody: "admin  ",
+		},
+		{
+			name:     "param host",
+			method:   http.MethodGet,
+			host:     "foo.example.com",
+			path:     "/",
+			wantCode: http.StatusOK,
+			wantBody: "tenant foo ",
+		},
+		{
+			name:     "param host with path params",
+			method:   http.MethodGet,
+			host:     "foo.example.com",
+			path:     "/users/12",
+			wantCode: http.StatusOK,
+			wantBody: "tenant user foo 12",
+		},
+		{
+			name:     "wildcard host matches many labels",
+			method:   http.MethodGet,
+			host:     "a.b.example.com",
+			path:     "/",
+			wantCode: http.StatusOK,
+			wantBody: "wildcard  ",
+		},
+		{
+			name:     "no host matched",
+			method:   http.MethodGet,
+			host:     "example.com",
+			path:     "/",
+			wantCode: http.StatusOK,
+			wantBody: "default  ",
+		},
+		{
+			name:     "fallback to default host",
+			method:   http.MethodGet,
+			host:     "foo.example.com",
+			path:     "/health",
+			wantCode: http.StatusOK,
+			wantBody: "health  ",
+		},
+		{
+			name:     "method of host route",
+			method:   http.MethodPost,
+			host:     "admin.example.com",
+			path:     "/users",
+			wantCode: http.StatusOK,
+			wantBody: "admin users  ",
+		},
+		{
+			name:     "host route not visible on other hosts",
+			method:   http.MethodPost,
+			host:     "foo.example.com",
+			path:     "/users",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.path, nil)
+			request.Host = testCase.host
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+		})
+	}
+}
+
+// TestHTTPServer_Host_middleware 测试主机路由与默认路由共用路由级中间件 且支持路由命名
+func TestHTTPServer_Host_middleware(t *testing.T) {
+	s := NewHTTPServer()
+	admin := s.Host("admin.example.com")
+	admin.GET("/users/:id", func(ctx *Context) {
+		ctx.RespData = append(ctx.RespData, "handler"...)
+	}).Name("admin user")
+
+	// 中间件在主机路由注册之后添加 同样生效
+	s.Use("/users/*", func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			ctx.RespData = append(ctx.RespData, "mdl "...)
+			next(ctx)
+		}
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/users/12", nil)
+	request.Host = "admin.example.com"
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, "mdl handler", recorder.Body.String())
+
+	u, err := s.URLFor("admin user", map[string]string{"id": "12"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/12", u)
+
+	routes := s.Routes()
+	assert.Len(t, routes, 1)
+	assert.Equal(t, "admin.example.com", routes[0].Host)
+	assert.Equal(t, "admin user", routes[0].Name)
+}
+
+// TestHTTPServer_Host_Illegal_Case 测试非法的主机模式
+func TestHTTPServer_Host_Illegal_Case(t *testing.T) {
+	s := NewHTTPServer()
+	testCases := []struct {
+		name    string
+		pattern string
+	}{
+		{name: "empty", pattern: ""},
+		{name: "empty label", pattern: "admin..com"},
+		{name: "wildcard not first", pattern: "admin.*.com"},
+		{name: "param without name", pattern: ":.example.com"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Panics(t, func() {
+				s.Host(testCase.pattern)
+			})
+		})
+	}
+}
+
+// TestRouter_hasRoute 测试判断路由森林中是否存在给定路径的路由 且判断时不分配内存
+func TestRouter_hasRoute(t *testing.T) {
+	r := newRouter()
+	mockHandleFunc := func(ctx *Context) {}
+	r.addRoute(http.MethodPost, "/users/:id", mockHandleFunc)
+	r.addRoute(http.MethodGet, "/orders/detail", mockHandleFunc)
+
+	assert.True(t, r.hasRoute("/users/1"))
+	assert.True(t, r.hasRoute("/orders/detail"))
+	// 没有业务处理函数的节点不算作路由
+	assert.False(t, r.hasRoute("/orders"))
+	assert.False(t, r.hasRoute("/health"))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.hasRoute("/users/1")
+		r.hasRoute("/health")
+	})
+	assert.Zero(t, allocs)
+}
@@ -1,9 +1,16 @@
 package web
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // 为确保HTTPServer结构体为Server接口的实现而定义的变量
@@ -11,9 +18,28 @@ var _ Server = &HTTPServer{}
 
 // HTTPServer HTTP服务器
 type HTTPServer struct {
-	router                                   // router 路由树
-	middlewares []Middleware                 // middlewares 中间件切片.表示HTTPServer需要按顺序执行的的中间件链
-	logFunc     func(msg string, arg ...any) // logFunc 日志函数
+	router                                               // router 路由树
+	middlewares             []Middleware                 // middlewares 中间件切片.表示HTTPServer需要按顺序执行的的中间件链
+	logFunc                 func(msg string, arg ...any) // logFunc 日志函数
+	handleMethodNotAllowed  bool                         // handleMethodNotAllowed 路径存在于其他HTTP动词的路由树上时 是否响应405而非404
+	notFoundHandler         HandleFunc                   // notFoundHandler 未命中路由时的处理函数
+	methodNotAllowedHandler HandleFunc                   // methodNotAllowedHandler HTTP动词不被允许时的处理函数
+	srv                     *http.Server                 // srv 底层的 http.Server 用于启动与优雅退出
+	srvMutex                sync.Mutex                   // srvMutex 保护srv的创建
+	startHooks              []Hook                       // startHooks 监听端口之后 启动服务之前按顺序执行的钩子
+	shutdownHooks           []Hook                       // shutdownHooks 优雅退出时按顺序执行的钩子
+	shutdownHookTimeout     time.Duration                // shutdownHookTimeout 执行所有退出钩子的时限 为0时使用 defaultShutdownHookTimeout
+	maxBodyBytes            int64                        // maxBodyBytes 请求体的最大字节数 为0时不限制
+	handler                 HandleFunc                   // handler 由中间件链包装后的入口处理函数 在配置中间件后构建一次
+	ctxPool                 sync.Pool                    // ctxPool 上下文对象池 用于复用 Context 减少每个请求的内存分配
+	debugPath               string                       // debugPath 路由调试端点的路由 为空时不注册调试端点
+	redirectTrailingSlash   bool                         // redirectTrailingSlash 是否将以"/"结尾的请求路径重定向到去掉末尾"/"的路径
+	redirectCleanPath       bool                         // redirectCleanPath 是否将包含连续"/"或"."与".."路由段的请求路径重定向到清理后的路径
+	caseInsensitive         bool                         // caseInsensitive 未命中路由时是否忽略大小写再次查找 并重定向到注册时的大小写
+	useRawPath              bool                         // useRawPath 是否使用转义后的原始路径查找路由
+	routerMutex             sync.Mutex                   // routerMutex 保护对主路由森林(即内嵌的router)的修改
+	snapshot                atomic.Pointer[router]       // snapshot 用于查找路由的只读路由森林快照 为nil时表示快照已失效
+	templateEngine          TemplateEngine               // templateEngine 模板引擎 用于 Context.Render 为nil时无法渲染模板
 }
 
 // NewHTTPServer 创建HTTP服务器
@@ -25,12 +51,21 @@ func NewHTTPServer(opts ...Option) *HTTPServer {
 		logFunc: func(msg string, arg ...any) {
 			fmt.Printf(msg, arg...)
 		},
+		srv: &http.Server{},
 	}
 
 	for _, opt := range opts {
 		opt(server)
 	}
 
+	// 所有Option都已生效 路由森林已确定 此时注册路由调试端点
+	if server.debugPath != "" {
+		server.GET(server.debugPath, server.handleRoutesDebug)
+	}
+
+	// 所有Option都已生效 中间件已配置完毕 此时构建中间件链
+	server.handler = server.buildHandler()
+
 	return server
 }
 
@@ -42,14 +77,72 @@ func ServerWithMiddleware(middlewares ...Middleware) Option {
 	}
 }
 
+// ServerWithRadixRouter 本函数用于将 HTTPServer 实例的路由树切换为压缩前缀树实现
+// 压缩前缀树中拥有公共前缀的静态路由段共享同一条路径 适用于同一层级下静态路由数量很多的场景
+// 路由的注册规则 冲突规则与优先级(静态路由 > 参数路由 > 通配符路由)与默认实现完全相同
+// Tips: 该Option会重建路由森林 因此必须在注册任何路由之前生效 通过 NewHTTPServer 传入即可保证这一点
+func ServerWithRadixRouter() Option {
+	return func(server *HTTPServer) {
+		server.router = newRadixRouter()
+	}
+}
+
+// ServerWithMethodNotAllowed 本函数用于开启405响应
+// 开启后 若请求的路径在其他HTTP动词的路由树上能找到路由 则响应405并在Allow响应头中给出允许的HTTP动词 而非响应404
+func ServerWithMethodNotAllowed() Option {
+	return func(server *HTTPServer) {
+		server.handleMethodNotAllowed = true
+	}
+}
+
+// ServerWithNotFoundHandler 本函数用于设置未命中路由时的处理函数
+// 调用该处理函数前 响应码已被设置为404 处理函数同样会经过 ServerWithMiddleware 注册的中间件
+func ServerWithNotFoundHandler(handleFunc HandleFunc) Option {
+	return func(server *HTTPServer) {
+		server.notFoundHandler = handleFunc
+	}
+}
+
+// ServerWithMethodNotAllowedHandler 本函数用于设置HTTP动词不被允许时的处理函数
+// 调用该处理函数前 响应码已被设置为405 Allow响应头也已被设置 处理函数同样会经过 ServerWithMiddleware 注册的中间件
+func ServerWithMethodNotAllowedHandler(handleFunc HandleFunc) Option {
+	return func(server *HTTPServer) {
+		server.methodNotAllowedHandler = handleFunc
+	}
+}
+
 // ServeHTTP WEB框架入口
 func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// 构建上下文
-	ctx := &Context{
-		Req:  r,
-		Resp: w,
+	// 限制请求体的大小 超过限制时读取请求体会返回错误
+	if s.maxBodyBytes > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+	}
+
+	// 从对象池中获取上下文
+	ctx, ok := s.ctxPool.Get().(*Context)
+	if !ok {
+		ctx = &Context{}
 	}
+	ctx.Req = r
+	ctx.Resp = w
+	ctx.server = s
+
+	// 直接以字面量创建的 HTTPServer 实例没有经过 NewHTTPServer 因此没有预先构建的中间件链
+	root := s.handler
+	if root == nil {
+		root = s.buildHandler()
+	}
+
+	// 查找路由树并执行命中的业务逻辑
+	root(ctx)
+
+	// 请求处理完毕 重置上下文并放回对象池
+	ctx.reset()
+	s.ctxPool.Put(ctx)
+}
 
+// buildHandler 构建由中间件链包装后的入口处理函数
+func (s *HTTPServer) buildHandler() HandleFunc {
 	// 执行中间件链
 	root := s.serve
 	for i := len(s.middlewares) - 1; i >= 0; i-- {
@@ -65,19 +158,34 @@ func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// 最后注册将响应数据和响应码写入到响应体中的中间件
 	// 确保这个中间件是执行完所有对响应码和响应数据的读写操作后才执行的
 	// 换言之,确保这个中间件是返回响应之前最后一个执行的
-	root = m(root)
-
-	// 查找路由树并执行命中的业务逻辑
-	root(ctx)
+	return m(root)
 }
 
 // flashResp 将响应数据和响应码写入到响应体中
+// HEAD请求只写入响应头和响应码 不写入响应数据
 func (s *HTTPServer) flashResp(ctx *Context) {
+	if ctx.Req.Method == http.MethodHead {
+		// 保留与GET请求一致的Content-Length
+		if ctx.Resp.Header().Get("Content-Length") == "" && len(ctx.RespData) != 0 {
+			ctx.Resp.Header().Set("Content-Length", strconv.Itoa(len(ctx.RespData)))
+		}
+
+		if ctx.RespStatusCode != 0 {
+			ctx.Resp.WriteHeader(ctx.RespStatusCode)
+		}
+		return
+	}
+
 	// 若使用者设置了响应码 则刷到响应上
 	if ctx.RespStatusCode != 0 {
 		ctx.Resp.WriteHeader(ctx.RespStatusCode)
 	}
 
+	// 没有响应数据时无需写入 例如204响应本身就不允许有响应体
+	if len(ctx.RespData) == 0 {
+		return
+	}
+
 	// 刷响应数据到响应上
 	n, err := ctx.Resp.Write(ctx.RespData)
 	if err != nil {
@@ -92,49 +200,220 @@ func (s *HTTPServer) flashResp(ctx *Context) {
 // serve 查找路由树并执行命中的业务逻辑
 func (s *HTTPServer) serve(ctx *Context) {
 	method := ctx.Req.Method
-	path := ctx.Req.URL.Path
-	targetNode, ok := s.findRoute(method, path)
+	path := s.requestPath(ctx.Req)
+	// 根据请求的主机选择路由森林 未注册主机模式时即为默认路由森林
+	// Tips: 使用只读快照查找路由 无需加锁 详见 currentRouter
+	rt := s.currentRouter().routerOf(ctx.Req.Host, path, &ctx.HostParams)
+
+	// 请求路径不是规范路径时 重定向到规范路径
+	if s.redirectCanonical(ctx, rt, path) {
+		return
+	}
+
+	// 路径参数直接写入上下文中预分配了容量的切片 避免查找路由时分配内存
+	if cap(ctx.PathParams) < rt.maxParams {
+		ctx.PathParams = make(Params, 0, rt.maxParams)
+	}
+	targetNode, ok := rt.findHandleRoute(method, path, &ctx.PathParams)
+
+	// HEAD请求没有注册对应的路由时 使用GET请求的路由处理 响应体会在 flashResp 中被丢弃
+	if !ok && method == http.MethodHead {
+		ctx.PathParams = ctx.PathParams[:0]
+		targetNode, ok = rt.findHandleRoute(http.MethodGet, path, &ctx.PathParams)
+	}
+
+	// 未命中路由时 丢弃查找过程中记录的路径参数
+	if !ok {
+		ctx.PathParams = ctx.PathParams[:0]
+	}
+
+	// 未命中路由时 尝试忽略大小写查找并重定向
+	if !ok && s.redirectCaseInsensitive(ctx, rt, path) {
+		return
+	}
+
+	// OPTIONS请求没有注册对应的路由时 自动应答 在Allow响应头中给出该路径支持的HTTP动词
+	if !ok && method == http.MethodOptions {
+		allow := rt.allowedMethods(path)
+		if len(allow) != 0 {
+			ctx.Resp.Header().Set("Allow", strings.Join(allow, ", "))
+			ctx.RespStatusCode = http.StatusNoContent
+			return
+		}
+	}
+
+	// 开启405响应时 若该路径在其他HTTP动词的路由树上能找到路由 则返回405
+	if !ok && s.handleMethodNotAllowed {
+		allow := rt.allowedMethods(path)
+		if len(allow) != 0 {
+			ctx.Resp.Header().Set("Allow", strings.Join(allow, ", "))
+			ctx.RespStatusCode = http.StatusMethodNotAllowed
+			if s.methodNotAllowedHandler == nil {
+				methodNotAllowed(ctx)
+				return
+			}
+			s.methodNotAllowedHandler(ctx)
+			return
+		}
+	}
+
 	// 没有在路由树中找到对应的路由节点 或 找到了路由节点的处理函数为空(即NPE:none pointer exception 的问题)
 	// 则返回404
-	if !ok || targetNode.node.HandleFunc == nil {
+	if !ok {
 		ctx.RespStatusCode = http.StatusNotFound
-		ctx.RespData = []byte("Not Found")
+		if s.notFoundHandler == nil {
+			notFound(ctx)
+			return
+		}
+		s.notFoundHandler(ctx)
 		return
 	}
 
-	// 命中节点则将路径参数名值对设置到上下文中
-	ctx.PathParams = targetNode.pathParams
-
 	// 命中节点则将节点的路由设置到上下文中
-	ctx.MatchRoute = targetNode.node.route
-	// 执行路由节点的处理函数
-	targetNode.node.HandleFunc(ctx)
+	// Tips: 路径参数在查找路由时已经写入到上下文中了
+	ctx.MatchRoute = targetNode.route
+	s.unescapeParams(ctx.PathParams)
+	// 执行路由节点的处理函数(已被路由级中间件包装)
+	targetNode.chain(ctx)
+}
+
+// notFound 默认的未命中路由处理函数 未设置 notFoundHandler 时使用
+func notFound(ctx *Context) {
+	ctx.RespStatusCode = http.StatusNotFound
+	ctx.RespData = []byte("Not Found")
+}
+
+// methodNotAllowed 默认的HTTP动词不被允许处理函数 未设置 methodNotAllowedHandler 时使用
+func methodNotAllowed(ctx *Context) {
+	ctx.RespStatusCode = http.StatusMethodNotAllowed
+	ctx.RespData = []byte("Method Not Allowed")
 }
 
 // Start 启动WEB服务器
+// 调用 Shutdown 优雅退出后 本方法返回nil
 func (s *HTTPServer) Start(addr string) error {
 	l, err := net.Listen("tcp", addr)
 	if err != nil {
 		return err
 	}
 
+	return s.serveListener(l, s.httpServer().Serve)
+}
+
+// serveListener 在给定的监听器上启动服务
+// serve为实际启动服务的函数 例如 http.Server 的 Serve 或 ServeTLS
+func (s *HTTPServer) serveListener(l net.Listener, serve func(l net.Listener) error) error {
 	// 在监听端口之后,启动服务之前做一些操作
 	// 例如在微服务框架中,启动服务之前需要注册服务
+	err := runHooks(context.Background(), s.startHooks)
+	if err != nil {
+		_ = l.Close()
+		return err
+	}
 
-	return http.Serve(l, s)
+	err = serve(l)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// httpServer 返回底层的 http.Server 若尚未创建则创建
+// Tips: 直接以字面量创建的 HTTPServer 实例(例如测试中的 &HTTPServer{router: newRouter()})没有经过 NewHTTPServer
+// Tips: 因此此处需要兜底创建
+func (s *HTTPServer) httpServer() *http.Server {
+	s.srvMutex.Lock()
+	defer s.srvMutex.Unlock()
+
+	if s.srv == nil {
+		s.srv = &http.Server{}
+	}
+	s.srv.Handler = s
+	return s.srv
+}
+
+// AddRoute 注册路由 路由不合规或与已注册的路由冲突时不会panic 而是返回 *RouteError 此时路由森林保持不变
+// 适用于根据配置或插件注册路由等不希望因注册失败而导致进程崩溃的场景
+// 可以通过 errors.Is 判断错误的类型 例如 errors.Is(err, ErrRouteConflict)
+// Tips: GET POST 等方法是本方法的panic版本
+func (s *HTTPServer) AddRoute(method string, path string, handleFunc HandleFunc) (*Route, error) {
+	if err := s.register(nil, method, path, handleFunc); err != nil {
+		return nil, err
+	}
+	return s.newRoute(method, path), nil
 }
 
 // GET 注册GET请求路由
-func (s *HTTPServer) GET(path string, handleFunc HandleFunc) {
+func (s *HTTPServer) GET(path string, handleFunc HandleFunc) *Route {
 	s.addRoute(http.MethodGet, path, handleFunc)
+	return s.newRoute(http.MethodGet, path)
 }
 
 // POST 注册POST请求路由
-func (s *HTTPServer) POST(path string, handleFunc HandleFunc) {
+func (s *HTTPServer) POST(path string, handleFunc HandleFunc) *Route {
 	s.addRoute(http.MethodPost, path, handleFunc)
+	return s.newRoute(http.MethodPost, path)
+}
+
+// PUT 注册PUT请求路由
+func (s *HTTPServer) PUT(path string, handleFunc HandleFunc) *Route {
+	s.addRoute(http.MethodPut, path, handleFunc)
+	return s.newRoute(http.MethodPut, path)
+}
+
+// PATCH 注册PATCH请求路由
+func (s *HTTPServer) PATCH(path string, handleFunc HandleFunc) *Route {
+	s.addRoute(http.MethodPatch, path, handleFunc)
+	return s.newRoute(http.MethodPatch, path)
+}
+
+// DELETE 注册DELETE请求路由
+func (s *HTTPServer) DELETE(path string, handleFunc HandleFunc) *Route {
+	s.addRoute(http.MethodDelete, path, handleFunc)
+	return s.newRoute(http.MethodDelete, path)
+}
+
+// HEAD 注册HEAD请求路由
+// 若未注册HEAD请求路由 则HEAD请求会使用对应的GET请求路由处理
+func (s *HTTPServer) HEAD(path string, handleFunc HandleFunc) *Route {
+	s.addRoute(http.MethodHead, path, handleFunc)
+	return s.newRoute(http.MethodHead, path)
+}
+
+// OPTIONS 注册OPTIONS请求路由
+// 若未注册OPTIONS请求路由 则OPTIONS请求会被自动应答
+func (s *HTTPServer) OPTIONS(path string, handleFunc HandleFunc) *Route {
+	s.addRoute(http.MethodOptions, path, handleFunc)
+	return s.newRoute(http.MethodOptions, path)
+}
+
+// CONNECT 注册CONNECT请求路由
+func (s *HTTPServer) CONNECT(path string, handleFunc HandleFunc) *Route {
+	s.addRoute(http.MethodConnect, path, handleFunc)
+	return s.newRoute(http.MethodConnect, path)
+}
+
+// TRACE 注册TRACE请求路由
+func (s *HTTPServer) TRACE(path string, handleFunc HandleFunc) *Route {
+	s.addRoute(http.MethodTrace, path, handleFunc)
+	return s.newRoute(http.MethodTrace, path)
+}
+
+// Any 为所有HTTP动词注册同一个路由
+// Tips: 返回的 Route 以GET请求路由为准 由于所有HTTP动词的路由相同 因此生成的URL也相同
+func (s *HTTPServer) Any(path string, handleFunc HandleFunc) *Route {
+	for _, method := range httpMethods {
+		s.addRoute(method, path, handleFunc)
+	}
+	return s.newRoute(http.MethodGet, path)
 }
 
-// Use 执行路由匹配 仅当匹配到路由时 才执行中间件
+// Use 为给定路由注册路由级中间件 仅当匹配到路由时 才执行中间件
+// 例如: s.Use("/admin/*", auth) 表示所有 /admin/ 下的路由都需要执行auth中间件
+// 一个请求需要执行的路由级中间件 由所有能覆盖其命中路由的节点上的中间件组成 按从根节点到叶子节点的顺序执行
+// 与 ServerWithMiddleware 注册的中间件不同 路由级中间件不会在未命中路由(例如404)时执行
 func (s *HTTPServer) Use(path string, middlewares ...Middleware) {
-	panic("implement me")
+	s.updateRouter(func(r *router) {
+		r.addMiddlewares(path, middlewares...)
+	})
 }
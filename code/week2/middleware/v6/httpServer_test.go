@@ -2,7 +2,9 @@ package web
 
 import (
 	"fmt"
+	"github.com/stretchr/testify/assert"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 )
@@ -57,3 +59,332 @@ func TestServer_serve(t *testing.T) {
 
 	_ = s.Start(":8081")
 }
+
+// TestHTTPServer_methods 测试各HTTP动词的路由注册 以及HEAD和OPTIONS请求的自动处理
+func TestHTTPServer_methods(t *testing.T) {
+	s := NewHTTPServer()
+	respMethod := func(ctx *Context) {
+		ctx.RespData = []byte(ctx.Req.Method)
+	}
+	s.GET("/user", func(ctx *Context) {
+		ctx.RespData = []byte("get")
+	})
+	s.PUT("/user", respMethod)
+	s.PATCH("/user", respMethod)
+	s.DELETE("/user", respMethod)
+	s.Any("/any", respMethod)
+	s.HEAD("/order", func(ctx *Context) {
+		ctx.Resp.Header().Set("X-Head", "registered")
+	})
+	s.GET("/order", respMethod)
+	s.OPTIONS("/order", func(ctx *Context) {
+		ctx.RespStatusCode = http.StatusOK
+		ctx.RespData = []byte("custom options")
+	})
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		wantCode   int
+		wantBody   string
+		wantHeader map[string]string
+	}{
+		{
+			name:     "put",
+			method:   http.MethodPut,
+			path:     "/user",
+			wantCode: http.StatusOK,
+			wantBody: http.MethodPut,
+		},
+		{
+			name:     "any trace",
+			method:   http.MethodTrace,
+			path:     "/any",
+			wantCode: http.StatusOK,
+			wantBody: http.MethodTrace,
+		},
+		{
+			name:     "head fallback to get",
+			method:   http.MethodHead,
+			path:     "/user",
+			wantCode: http.StatusOK,
+			wantBody: "",
+			wantHeader: map[string]string{
+				"Content-Length": "3",
+			},
+		},
+		{
+			name:     "registered head",
+			method:   http.MethodHead,
+			path:     "/order",
+			wantCode: http.StatusOK,
+			wantBody: "",
+			wantHeader: map[string]string{
+				"X-Head": "registered",
+			},
+		},
+		{
+			name:     "automatic options",
+			method:   http.MethodOptions,
+			path:     "/user",
+			wantCode: http.StatusNoContent,
+			wantBody: "",
+			wantHeader: map[string]string{
+				"Allow": "DELETE, GET, HEAD, OPTIONS, PATCH, PUT",
+			},
+		},
+		{
+			name:     "registered options",
+			method:   http.MethodOptions,
+			path:     "/order",
+			wantCode: http.StatusOK,
+			wantBody: "custom options",
+		},
+		{
+			name:     "options not found",
+			method:   http.MethodOptions,
+			path:     "/login",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.path, nil)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+			for key, value := range testCase.wantHeader {
+				assert.Equal(t, value, recorder.Header().Get(key))
+			}
+		})
+	}
+}
+
+// TestHTTPServer_methodNotAllowed 测试路径存在于其他HTTP动词的路由树上时的405响应 以及自定义404/405处理函数
+func TestHTTPServer_methodNotAllowed(t *testing.T) {
+	mockHandleFunc := func(ctx *Context) {
+		ctx.RespData = []byte("ok")
+	}
+
+	// recordMiddleware 用于验证自定义的处理函数同样会经过中间件
+	recordMiddleware := func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			ctx.Resp.Header().Set("X-Middleware", "executed")
+		}
+	}
+
+	defaultServer := NewHTTPServer()
+	defaultServer.GET("/user/:id", mockHandleFunc)
+
+	enabledServer := NewHTTPServer(ServerWithMethodNotAllowed())
+	enabledServer.GET("/user/:id", mockHandleFunc)
+	enabledServer.PUT("/user/:id", mockHandleFunc)
+
+	customServer := NewHTTPServer(
+		ServerWithMethodNotAllowed(),
+		ServerWithMiddleware(recordMiddleware),
+		ServerWithNotFoundHandler(func(ctx *Context) {
+			ctx.RespData = []byte("custom not found")
+		}),
+		ServerWithMethodNotAllowedHandler(func(ctx *Context) {
+			ctx.RespData = []byte("custom method not allowed: " + ctx.Resp.Header().Get("Allow"))
+		}),
+	)
+	customServer.POST("/order", mockHandleFunc)
+
+	testCases := []struct {
+		name       string
+		server     *HTTPServer
+		method     string
+		path       string
+		wantCode   int
+		wantBody   string
+		wantHeader map[string]string
+	}{
+		{
+			name:     "disabled by default",
+			server:   defaultServer,
+			method:   http.MethodPost,
+			path:     "/user/1",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:     "method not allowed",
+			server:   enabledServer,
+			method:   http.MethodPost,
+			path:     "/user/1",
+			wantCode: http.StatusMethodNotAllowed,
+			wantBody: "Method Not Allowed",
+			wantHeader: map[string]string{
+				"Allow": "GET, HEAD, OPTIONS, PUT",
+			},
+		},
+		{
+			name:     "not found in any tree",
+			server:   enabledServer,
+			method:   http.MethodPost,
+			path:     "/order",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:     "custom method not allowed",
+			server:   customServer,
+			method:   http.MethodGet,
+			path:     "/order",
+			wantCode: http.StatusMethodNotAllowed,
+			wantBody: "custom method not allowed: OPTIONS, POST",
+			wantHeader: map[string]string{
+				"X-Middleware": "executed",
+			},
+		},
+		{
+			name:     "custom not found",
+			server:   customServer,
+			method:   http.MethodGet,
+			path:     "/user",
+			wantCode: http.StatusNotFound,
+			wantBody: "custom not found",
+			wantHeader: map[string]string{
+				"X-Middleware": "executed",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.path, nil)
+			recorder := httptest.NewRecorder()
+			testCase.server.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+			for key, value := range testCase.wantHeader {
+				assert.Equal(t, value, recorder.Header().Get(key))
+			}
+		})
+	}
+}
+
+// TestHTTPServer_regex 测试通过 Context.PathValue 获取正则路由段的值
+func TestHTTPServer_regex(t *testing.T) {
+	s := NewHTTPServer()
+	s.GET("/file/:file((?P<name>\\w+)\\.(?P<ext>png|jpg))", func(ctx *Context) {
+		ctx.RespData = []byte(ctx.PathValue("file").value + " " + ctx.PathValue("name").value + " " + ctx.PathValue("ext").value)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/file/logo.jpg", nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, "logo.jpg logo jpg", recorder.Body.String())
+}
+
+// nopResponseWriter 不做任何事情的 http.ResponseWriter 避免基准测试中记录响应的开销影响结果
+type nopResponseWriter struct {
+	header http.Header
+}
+
+func (w *nopResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *nopResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *nopResponseWriter) WriteHeader(statusCode int) {}
+
+// legacyServeHTTP 引入上下文对象池和预先构建中间件链之前的 ServeHTTP 实现 用于基准测试对比
+// 每次请求都会创建新的上下文 并重新构建中间件链
+func legacyServeHTTP(s *HTTPServer, w http.ResponseWriter, r *http.Request) {
+	ctx := &Context{
+		Req:  r,
+		Resp: w,
+	}
+
+	root := s.serve
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		root = s.middlewares[i](root)
+	}
+
+	var m Middleware = func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			s.flashResp(ctx)
+		}
+	}
+	root = m(root)
+
+	root(ctx)
+}
+
+// BenchmarkHTTPServer_ServeHTTP 对比静态路由 参数路由 通配符路由下 对象池实现与旧实现的性能
+// 运行方式: go test -bench=BenchmarkHTTPServer_ServeHTTP -benchmem -run=^$
+func BenchmarkHTTPServer_ServeHTTP(b *testing.B) {
+	nopMiddleware := func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+		}
+	}
+	s := NewHTTPServer(ServerWithMiddleware(nopMiddleware, nopMiddleware, nopMiddleware))
+	respData := []byte("ok")
+	handleFunc := func(ctx *Context) {
+		ctx.RespData = respData
+	}
+	s.GET("/user/home/profile", handleFunc)
+	s.GET("/order/:id/detail", handleFunc)
+	s.GET("/static/*", handleFunc)
+
+	benchCases := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "static",
+			path: "/user/home/profile",
+		},
+		{
+			name: "param",
+			path: "/order/123/detail",
+		},
+		{
+			name: "wildcard",
+			path: "/static/app.js",
+		},
+	}
+
+	serveFuncs := []struct {
+		name  string
+		serve func(w http.ResponseWriter, r *http.Request)
+	}{
+		{
+			name:  "pool",
+			serve: s.ServeHTTP,
+		},
+		{
+			name: "legacy",
+			serve: func(w http.ResponseWriter, r *http.Request) {
+				legacyServeHTTP(s, w, r)
+			},
+		},
+	}
+
+	for _, benchCase := range benchCases {
+		for _, serveFunc := range serveFuncs {
+			b.Run(benchCase.name+"/"+serveFunc.name, func(b *testing.B) {
+				request := httptest.NewRequest(http.MethodGet, benchCase.path, nil)
+				writer := &nopResponseWriter{header: http.Header{}}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					serveFunc.serve(writer, request)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package web
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// RouteInfo 已注册路由的信息 用于查看HTTP服务器提供了哪些路由
+type RouteInfo struct {
+	Host        string   `json:"host,omitempty"` // Host 路由绑定的主机模式 默认路由森林中的路由为空字符串
+	Method      string   `json:"method"`         // Method 路由的HTTP动词
+	Pattern     string   `json:"pattern"`        // Pattern 注册时的完整路由 例如 /user/:id<int>
+	Name        string   `json:"name,omitempty"` // Name 路由名称 未命名时为空字符串
+	Handler     string   `json:"handler"`        // Handler 业务处理函数的函数名
+	Middlewares []string `json:"middlewares"`    // Middlewares 命中该路由时执行的路由级中间件的函数名 按执行顺序排列 不包含全局中间件
+	NodeType    string   `json:"nodeType"`       // NodeType 路由末尾节点的类型 取值为 static regex constraint param any
+}
+
+// routesDebugInfo 路由调试端点的响应
+type routesDebugInfo struct {
+	Routes []RouteInfo       `json:"routes"` // Routes 所有已注册的路由
+	Trees  map[string]string `json:"trees"`  // Trees HTTP动词到路由树文本的映射 包含通过 Host 注册的路由树
+}
+
+// ServerWithRoutesDebug 在给定路由上注册一个GET请求的调试端点 以JSON格式输出所有已注册的路由与路由树
+// 调试端点会暴露服务的全部路由 因此仅建议在开发环境或内网中启用
+// Tips: 调试端点在所有Option生效之后才注册 因此与 ServerWithRadixRouter 等Option的顺序无关
+func ServerWithRoutesDebug(path string) Option {
+	return func(server *HTTPServer) {
+		server.debugPath = path
+	}
+}
+
+// Routes 返回所有已注册的路由 按主机模式 HTTP动词和路由排序
+func (s *HTTPServer) Routes() []RouteInfo {
+	rt := s.currentRouter()
+	// 路由与路由名称的映射 key为主机模式 HTTP动词与路由的拼接
+	names := make(map[string]string, len(rt.names))
+	for name, named := range rt.names {
+		names[named.host+" "+named.method+" "+named.path] = name
+	}
+
+	res := rt.routeInfos("", names)
+	for _, h := range rt.hosts {
+		res = append(res, h.routeInfos(h.pattern, names)...)
+	}
+
+	slices.SortFunc(res, func(a, b RouteInfo) int {
+		if c := strings.Compare(a.Host, b.Host); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Method, b.Method); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Pattern, b.Pattern)
+	})
+	return res
+}
+
+// routeInfos 返回当前路由森林中所有已注册的路由 顺序不固定
+func (r *router) routeInfos(host string, names map[string]string) []RouteInfo {
+	res := make([]RouteInfo, 0)
+	for method, root := range r.trees {
+		root.walk(func(n *node) {
+			if n.HandleFunc == nil {
+				return
+			}
+
+			pattern := "/" + strings.TrimPrefix(n.route, "/")
+			mdls := make([]string, 0, len(n.matchedMdls))
+			for _, mdl := range n.matchedMdls {
+				mdls = append(mdls, funcName(mdl))
+			}
+
+			res = append(res, RouteInfo{
+				Host:        host,
+				Method:      method,
+				Pattern:     pattern,
+				Name:        names[host+" "+method+" "+pattern],
+				Handler:     funcName(n.HandleFunc),
+				Middlewares: mdls,
+				NodeType:    n.typeName(),
+			})
+		})
+	}
+	return res
+}
+
+// Tree 以树形文本输出给定HTTP动词的路由树 用于排查路由的优先级与冲突问题
+// 同一层的子节点按照查找时的优先级排列 即: 静态子节点(按字典序) > 正则子节点 > 带约束的参数子节点 > 参数子节点 > 通配符子节点
+// 有业务处理函数的节点会在末尾输出函数名 例如:
+//
+//	GET /
+//	└── user [static]
+//	    ├── home [static] => main.home
+//	    └── :id<int> [constraint] => main.user
+//
+// 通过 Host 注册的路由树按主机模式的匹配优先级依次输出在默认路由树之后 根节点以主机模式开头 例如:
+//
+//	GET admin.example.com/
+//	└── dashboard [static] => main.dashboard
+//
+// 给定的HTTP动词没有路由树时返回空字符串
+func (s *HTTPServer) Tree(method string) string {
+	rt := s.currentRouter()
+	builder := &strings.Builder{}
+	rt.dumpTree(builder, method, "")
+	for _, h := range rt.hosts {
+		h.dumpTree(builder, method, h.pattern)
+	}
+	return builder.String()
+}
+
+// dumpTree 将当前路由森林中给定HTTP动词的路由树以树形文本写入builder host为路由树所属的主机模式
+// 给定的HTTP动词没有路由树时不写入任何内容
+func (r *router) dumpTree(builder *strings.Builder, method string, host string) {
+	root, ok := r.trees[method]
+	if !ok {
+		return
+	}
+
+	builder.WriteString(method + " " + host + "/")
+	if root.HandleFunc != nil {
+		builder.WriteString(" => " + funcName(root.HandleFunc))
+	}
+	builder.WriteByte('\n')
+	root.dump(builder, "")
+}
+
+// dump 将当前节点的子节点以树形文本写入builder prefix为子节点所在层的缩进
+func (n *node) dump(builder *strings.Builder, prefix string) {
+	children := n.priorityChildren()
+	for i, child := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		builder.WriteString(prefix + branch + child.path + " [" + child.typeName() + "]")
+		if child.HandleFunc != nil {
+			builder.WriteString(" => " + funcName(child.HandleFunc))
+		}
+		builder.WriteByte('\n')
+		child.dump(builder, prefix+indent)
+	}
+}
+
+// priorityChildren 按照查找时的优先级返回当前节点的所有子节点 静态子节点之间按字典序排列
+func (n *node) priorityChildren() []*node {
+	res := n.staticChildren()
+	slices.SortFunc(res, func(a, b *node) int {
+		return strings.Compare(a.path, b.path)
+	})
+
+	for _, child := range []*node{n.regChild, n.constraintChild, n.paramChild, n.wildcardChild} {
+		if child != nil {
+			res = append(res, child)
+		}
+	}
+	return res
+}
+
+// typeName 返回节点类型的名称
+func (n *node) typeName() string {
+	switch n.typ {
+	case nodeTypeReg:
+		return "regex"
+	case nodeTypeParam:
+		if n.validator != nil {
+			return "constraint"
+		}
+		return "param"
+	case nodeTypeAny:
+		return "any"
+	default:
+		return "static"
+	}
+}
+
+// handleRoutesDebug 路由调试端点的业务处理函数
+func (s *HTTPServer) handleRoutesDebug(ctx *Context) {
+	rt := s.currentRouter()
+	info := routesDebugInfo{
+		Routes: s.Routes(),
+		Trees:  make(map[string]string, len(rt.trees)),
+	}
+	for method := range rt.trees {
+		info.Trees[method] = s.Tree(method)
+	}
+	for _, h := range rt.hosts {
+		for method := range h.trees {
+			info.Trees[method] = s.Tree(method)
+		}
+	}
+	_ = ctx.RespJSONOK(info)
+}
+
+// funcName 通过反射获取函数的函数名 例如 web.(*HTTPServer).handleRoutesDebug-fm
+// 闭包的函数名形如 main.main.func1
+func funcName(fn any) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return ""
+	}
+
+	f := runtime.FuncForPC(value.Pointer())
+	if f == nil {
+		return ""
+	}
+	return f.Name()
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// inspectHandle 用于测试输出业务处理函数的函数名
+func inspectHandle(ctx *Context) {}
+
+// inspectMiddleware 用于测试输出中间件的函数名
+func inspectMiddleware(next HandleFunc) HandleFunc {
+	return next
+}
+
+// newInspectServer 创建一个注册了各类路由的HTTP服务器
+func newInspectServer(opts ...Option) *HTTPServer {
+	s := NewHTTPServer(opts...)
+	s.GET("/", inspectHandle).Name("home")
+	s.GET("/user/home", inspectHandle)
+	s.GET("/user/:id<int>", inspectHandle).Name("user")
+	s.GET("/user/:name", inspectHandle)
+	s.GET("/user/:file(\\w+\\.png)", inspectHandle)
+	s.GET("/static/*", inspectHandle)
+	s.POST("/user", inspectHandle)
+	s.Use("/user/*", inspectMiddleware)
+	return s
+}
+
+// TestHTTPServer_Routes 测试获取所有已注册的路由
+func TestHTTPServer_Routes(t *testing.T) {
+	s := newInspectServer()
+	handler := "web.inspectHandle"
+	mdl := "web.inspectMiddleware"
+
+	wantRoutes := []RouteInfo{
+		{Method: http.MethodGet, Pattern: "/", Name: "home", Handler: handler, Middlewares: []string{}, NodeType: "static"},
+		{Method: http.MethodGet, Pattern: "/static/*", Handler: handler, Middlewares: []string{}, NodeType: "any"},
+		{Method: http.MethodGet, Pattern: "/user/:file(\\w+\\.png)", Handler: handler, Middlewares: []string{mdl}, NodeType: "regex"},
+		{Method: http.MethodGet, Pattern: "/user/:id<int>", Name: "user", Handler: handler, Middlewares: []string{mdl}, NodeType: "constraint"},
+		{Method: http.MethodGet, Pattern: "/user/:name", Handler: handler, Middlewares: []string{mdl}, NodeType: "param"},
+		{Method: http.MethodGet, Pattern: "/user/home", Handler: handler, Middlewares: []string{mdl}, NodeType: "static"},
+		{Method: http.MethodPost, Pattern: "/user", Handler: handler, Middlewares: []string{}, NodeType: "static"},
+	}
+	assert.Equal(t, wantRoutes, s.Routes())
+}
+
+// TestHTTPServer_Tree 测试以树形文本输出路由树 同一层的子节点按照查找时的优先级排列
+func TestHTTPServer_Tree(t *testing.T) {
+	s := newInspectServer()
+	wantTree := "GET / => web.inspectHandle\n" +
+		"├── static [static]\n" +
+		"│   └── * [any] => web.inspectHandle\n" +
+		"└── user [static]\n" +
+		"    ├── home [static] => web.inspectHandle\n" +
+		"    ├── :file(\\w+\\.png) [regex] => web.inspectHandle\n" +
+		"    ├── :id<int> [constraint] => web.inspectHandle\n" +
+		"    └── :name [param] => web.inspectHandle\n"
+	assert.Equal(t, wantTree, s.Tree(http.MethodGet))
+	assert.Equal(t, "POST /\n└── user [static] => web.inspectHandle\n", s.Tree(http.MethodPost))
+	assert.Equal(t, "", s.Tree(http.MethodDelete))
+
+	// 主机模式的路由树按匹配优先级输出在默认路由树之后
+	s.Host("*.example.com").PUT("/user", inspectHandle)
+	s.Host("admin.example.com").GET("/dashboard", inspectHandle)
+	s.Host("admin.example.com").POST("/", inspectHandle)
+	wantTree += "GET admin.example.com/\n" +
+		"└── dashboard [static] => web.inspectHandle\n"
+	assert.Equal(t, wantTree, s.Tree(http.MethodGet))
+	assert.Equal(t, "POST /\n└── user [static] => web.inspectHandle\n"+
+		"POST admin.example.com/ => web.inspectHandle\n", s.Tree(http.MethodPost))
+	assert.Equal(t, "PUT *.example.com/\n└── user [static] => web.inspectHandle\n", s.Tree(http.MethodPut))
+}
+
+// TestServerWithRoutesDebug 测试路由调试端点
+func TestServerWithRoutesDebug(t *testing.T) {
+	// 调试端点的注册与Option的顺序无关
+	s := newInspectServer(ServerWithRoutesDebug("/debug/routes"), ServerWithRadixRouter())
+	s.Host("admin.example.com").PUT("/user", inspectHandle)
+
+	request := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	info := routesDebugInfo{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &info))
+	assert.Equal(t, s.Routes(), info.Routes)
+	assert.Contains(t, info.Routes, RouteInfo{
+		Method:      http.MethodGet,
+		Pattern:     "/debug/routes",
+		Handler:     "web.(*HTTPServer).handleRoutesDebug-fm",
+		Middlewares: []string{},
+		NodeType:    "static",
+	})
+	assert.Equal(t, s.Tree(http.MethodPost), info.Trees[http.MethodPost])
+	// 只在主机模式上注册过的HTTP动词同样输出路由树
+	assert.Equal(t, s.Tree(http.MethodPut), info.Trees[http.MethodPut])
+	assert.Contains(t, info.Trees[http.MethodPut], "PUT admin.example.com/")
+
+	// 未启用时不注册调试端点
+	s = newInspectServer()
+	recorder = httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// defaultShutdownHookTimeout 退出钩子默认的执行时限 详见 ServerWithShutdownHookTimeout
+const defaultShutdownHookTimeout = 5 * time.Second
+
+// Hook 生命周期钩子 用于在服务启动或退出时执行一些操作
+// 例如: 启动时向注册中心注册服务 退出时关闭数据库连接
+type Hook func(ctx context.Context) error
+
+// ServerWithOnStart 本函数用于为 HTTPServer 实例添加启动钩子
+// 启动钩子在监听端口之后 启动服务之前按注册顺序执行
+func ServerWithOnStart(hooks ...Hook) Option {
+	return func(server *HTTPServer) {
+		server.OnStart(hooks...)
+	}
+}
+
+// ServerWithOnShutdown 本函数用于为 HTTPServer 实例添加退出钩子
+// 退出钩子在所有处理中的请求完成后 按注册顺序执行
+func ServerWithOnShutdown(hooks ...Hook) Option {
+	return func(server *HTTPServer) {
+		server.OnShutdown(hooks...)
+	}
+}
+
+// ServerWithShutdownHookTimeout 本函数用于设置 HTTPServer 实例执行所有退出钩子的时限 默认为5秒
+// 退出钩子使用独立的context 而不是 Shutdown 传入的ctx 因为等待请求完成时可能已经耗尽了ctx的时限
+func ServerWithShutdownHookTimeout(timeout time.Duration) Option {
+	return func(server *HTTPServer) {
+		server.shutdownHookTimeout = timeout
+	}
+}
+
+// OnStart 注册启动钩子
+// 若任意一个启动钩子返回错误 则 Start 不会启动服务 并返回所有启动钩子的错误
+func (s *HTTPServer) OnStart(hooks ...Hook) {
+	s.startHooks = append(s.startHooks, hooks...)
+}
+
+// OnShutdown 注册退出钩子
+func (s *HTTPServer) OnShutdown(hooks ...Hook) {
+	s.shutdownHooks = append(s.shutdownHooks, hooks...)
+}
+
+// Shutdown 优雅退出WEB服务器
+// 1. 关闭监听 不再接受新的连接
+// 2. 等待处理中的请求完成 直到ctx超时或被取消
+// 3. 按注册顺序执行退出钩子
+// 以上步骤中的所有错误会被合并后返回 即使等待请求完成时超时 退出钩子依旧会被执行
+// Tips: 退出钩子的ctx保留了传入ctx中的值 但不会随传入ctx超时或取消 其时限由 ServerWithShutdownHookTimeout 单独设置
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	var errs []error
+	err := s.httpServer().Shutdown(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	timeout := s.shutdownHookTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownHookTimeout
+	}
+	hookCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	err = runHooks(hookCtx, s.shutdownHooks)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// runHooks 按顺序执行给定的钩子 某个钩子返回错误时不会中断后续钩子的执行
+// 所有钩子的错误会被合并后返回 全部成功时返回nil
+func runHooks(ctx context.Context, hooks []Hook) error {
+	var errs []error
+	for _, hook := range hooks {
+		err := hook(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,202 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// freeAddr 获取一个本机可用的监听地址
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+// TestHTTPServer_Shutdown 测试优雅退出: 处理中的请求完成后才退出 且钩子按顺序执行
+func TestHTTPServer_Shutdown(t *testing.T) {
+	records := make([]string, 0, 4)
+	recordHook := func(name string) Hook {
+		return func(ctx context.Context) error {
+			records = append(records, name)
+			return nil
+		}
+	}
+
+	s := NewHTTPServer(
+		ServerWithOnStart(recordHook("start1"), recordHook("start2")),
+		ServerWithOnShutdown(recordHook("shutdown1")),
+	)
+	s.OnShutdown(recordHook("shutdown2"))
+
+	// 处理函数阻塞 直到测试允许其返回
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	s.GET("/slow", func(ctx *Context) {
+		close(entered)
+		<-release
+		ctx.RespData = []byte("done")
+	})
+
+	addr := freeAddr(t)
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- s.Start(addr)
+	}()
+
+	// 等待服务启动并发出一个慢请求
+	respBody := make(chan string, 1)
+	go func() {
+		var resp *http.Response
+		var err error
+		for i := 0; i < 50; i++ {
+			resp, err = http.Get("http://" + addr + "/slow")
+			if err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err != nil {
+			respBody <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		respBody <- string(data)
+	}()
+	<-entered
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownErr <- s.Shutdown(ctx)
+	}()
+
+	// 退出过程中 处理中的请求不应被中断
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("处理中的请求未完成时 Shutdown 不应返回: %v", err)
+	default:
+	}
+
+	close(release)
+	assert.Equal(t, "done", <-respBody)
+	assert.NoError(t, <-shutdownErr)
+	assert.NoError(t, <-startErr)
+	assert.Equal(t, []string{"start1", "start2", "shutdown1", "shutdown2"}, records)
+}
+
+// TestHTTPServer_Shutdown_timeout 测试等待请求完成超时时 退出钩子依旧执行 且错误被合并返回
+func TestHTTPServer_Shutdown_timeout(t *testing.T) {
+	hookErr := errors.New("hook error")
+	hookExecuted := false
+	var hookCtxErr error
+	s := NewHTTPServer(ServerWithOnShutdown(
+		func(ctx context.Context) error {
+			// 等待请求完成时已耗尽传入ctx的时限 退出钩子的ctx不应随之失效
+			hookCtxErr = ctx.Err()
+			return hookErr
+		},
+		func(ctx context.Context) error {
+			hookExecuted = true
+			return nil
+		},
+	))
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	s.GET("/slow", func(ctx *Context) {
+		close(entered)
+		<-release
+	})
+
+	addr := freeAddr(t)
+	go func() {
+		_ = s.Start(addr)
+	}()
+	go func() {
+		for i := 0; i < 50; i++ {
+			resp, err := http.Get("http://" + addr + "/slow")
+			if err == nil {
+				_ = resp.Body.Close()
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := s.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, hookErr)
+	assert.True(t, hookExecuted)
+	assert.NoError(t, hookCtxErr)
+}
+
+// TestHTTPServer_Shutdown_hookTimeout 测试退出钩子超过时限时被取消 且后续钩子依旧执行
+func TestHTTPServer_Shutdown_hookTimeout(t *testing.T) {
+	type ctxKey struct{}
+	var value any
+	hookExecuted := false
+	s := NewHTTPServer(
+		ServerWithShutdownHookTimeout(50*time.Millisecond),
+		ServerWithOnShutdown(
+			func(ctx context.Context) error {
+				value = ctx.Value(ctxKey{})
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			func(ctx context.Context) error {
+				hookExecuted = true
+				return nil
+			},
+		),
+	)
+
+	// 传入ctx的时限远大于钩子的时限 钩子的ctx依旧会在钩子的时限到达时取消
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "v"), time.Minute)
+	defer cancel()
+	begin := time.Now()
+	err := s.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(begin), 5*time.Second)
+	assert.True(t, hookExecuted)
+	assert.Equal(t, "v", value)
+}
+
+// TestHTTPServer_Start_hookError 测试启动钩子返回错误时 服务不会启动
+func TestHTTPServer_Start_hookError(t *testing.T) {
+	firstErr := errors.New("first error")
+	secondErr := errors.New("second error")
+	s := NewHTTPServer(ServerWithOnStart(
+		func(ctx context.Context) error {
+			return firstErr
+		},
+		func(ctx context.Context) error {
+			return secondErr
+		},
+	))
+
+	addr := freeAddr(t)
+	err := s.Start(addr)
+	assert.ErrorIs(t, err, firstErr)
+	assert.ErrorIs(t, err, secondErr)
+
+	// 服务未启动 端口应当已被释放
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	_ = l.Close()
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ServerWithReadHeaderTimeout 本函数用于设置读取请求头的超时时间
+// 用于防范slowloris攻击 即客户端以极慢的速度发送请求头 长期占用连接
+func ServerWithReadHeaderTimeout(timeout time.Duration) Option {
+	return func(server *HTTPServer) {
+		server.httpServer().ReadHeaderTimeout = timeout
+	}
+}
+
+// ServerWithReadTimeout 本函数用于设置读取整个请求(包括请求体)的超时时间
+func ServerWithReadTimeout(timeout time.Duration) Option {
+	return func(server *HTTPServer) {
+		server.httpServer().ReadTimeout = timeout
+	}
+}
+
+// ServerWithWriteTimeout 本函数用于设置写入响应的超时时间
+func ServerWithWriteTimeout(timeout time.Duration) Option {
+	return func(server *HTTPServer) {
+		server.httpServer().WriteTimeout = timeout
+	}
+}
+
+// ServerWithIdleTimeout 本函数用于设置开启keep-alive时 等待下一个请求的超时时间
+func ServerWithIdleTimeout(timeout time.Duration) Option {
+	return func(server *HTTPServer) {
+		server.httpServer().IdleTimeout = timeout
+	}
+}
+
+// ServerWithMaxHeaderBytes 本函数用于设置请求头的最大字节数
+func ServerWithMaxHeaderBytes(maxBytes int) Option {
+	return func(server *HTTPServer) {
+		server.httpServer().MaxHeaderBytes = maxBytes
+	}
+}
+
+// ServerWithMaxBodyBytes 本函数用于设置请求体的最大字节数
+// 请求体超过该大小时 Context.BindJSON 和 Context.FormValue 会返回错误 并将响应设置为413
+func ServerWithMaxBodyBytes(maxBytes int64) Option {
+	return func(server *HTTPServer) {
+		server.maxBodyBytes = maxBytes
+	}
+}
+
+// isBodyTooLarge 判断读取请求体时的错误是否为请求体超过最大字节数
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
@@ -0,0 +1,172 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestServerWithTimeouts 测试超时与请求头大小相关的Option被设置到底层的 http.Server 上
+func TestServerWithTimeouts(t *testing.T) {
+	s := NewHTTPServer(
+		ServerWithReadHeaderTimeout(time.Second),
+		ServerWithReadTimeout(2*time.Second),
+		ServerWithWriteTimeout(3*time.Second),
+		ServerWithIdleTimeout(4*time.Second),
+		ServerWithMaxHeaderBytes(1<<10),
+	)
+
+	srv := s.httpServer()
+	assert.Equal(t, time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 2*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 3*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 4*time.Second, srv.IdleTimeout)
+	assert.Equal(t, 1<<10, srv.MaxHeaderBytes)
+}
+
+// TestServerWithMaxBodyBytes 测试请求体超过最大字节数时响应413
+func TestServerWithMaxBodyBytes(t *testing.T) {
+	s := NewHTTPServer(ServerWithMaxBodyBytes(16))
+	s.POST("/json", func(ctx *Context) {
+		user := map[string]string{}
+		err := ctx.BindJSON(&user)
+		if err != nil {
+			return
+		}
+		ctx.RespData = []byte(user["name"])
+	})
+	s.POST("/form", func(ctx *Context) {
+		name := ctx.FormValue("name")
+		if name.err != nil {
+			return
+		}
+		ctx.RespData = []byte(name.value)
+	})
+
+	testCases := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:        "json within limit",
+			path:        "/json",
+			contentType: "application/json",
+			body:        `{"name":"Tom"}`,
+			wantCode:    http.StatusOK,
+			wantBody:    "Tom",
+		},
+		{
+			name:        "json too large",
+			path:        "/json",
+			contentType: "application/json",
+			body:        `{"name":"` + strings.Repeat("a", 32) + `"}`,
+			wantCode:    http.StatusRequestEntityTooLarge,
+			wantBody:    "Request Entity Too Large",
+		},
+		{
+			name:        "form within limit",
+			path:        "/form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=Tom",
+			wantCode:    http.StatusOK,
+			wantBody:    "Tom",
+		},
+		{
+			name:        "form too large",
+			path:        "/form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=" + strings.Repeat("a", 32),
+			wantCode:    http.StatusRequestEntityTooLarge,
+			wantBody:    "Request Entity Too Large",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, testCase.path, strings.NewReader(testCase.body))
+			request.Header.Set("Content-Type", testCase.contentType)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+		})
+	}
+}
+
+// TestSafeContext_MaxBodyBytes 测试通过 SafeContext 读取超过最大字节数的请求体时同样响应413
+func TestSafeContext_MaxBodyBytes(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		read        func(s *SafeContext) error
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:        "json within limit",
+			contentType: "application/json",
+			body:        `{"name":"Tom"}`,
+			read: func(s *SafeContext) error {
+				user := map[string]string{}
+				return s.BindJSON(&user)
+			},
+		},
+		{
+			name:        "json too large",
+			contentType: "application/json",
+			body:        `{"name":"` + strings.Repeat("a", 32) + `"}`,
+			read: func(s *SafeContext) error {
+				user := map[string]string{}
+				return s.BindJSON(&user)
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantBody: "Request Entity Too Large",
+		},
+		{
+			name:        "form within limit",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=Tom",
+			read: func(s *SafeContext) error {
+				return s.FormValue("name").err
+			},
+		},
+		{
+			name:        "form too large",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=" + strings.Repeat("a", 32),
+			read: func(s *SafeContext) error {
+				return s.FormValue("name").err
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantBody: "Request Entity Too Large",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testCase.body))
+			request.Header.Set("Content-Type", testCase.contentType)
+			request.Body = http.MaxBytesReader(recorder, request.Body, 16)
+			s := &SafeContext{Context: Context{Req: request, Resp: recorder}}
+
+			err := testCase.read(s)
+			if testCase.wantCode == 0 {
+				assert.NoError(t, err)
+			} else {
+				var maxBytesErr *http.MaxBytesError
+				assert.ErrorAs(t, err, &maxBytesErr)
+			}
+			assert.Equal(t, testCase.wantCode, s.Context.RespStatusCode)
+			assert.Equal(t, testCase.wantBody, string(s.Context.RespData))
+		})
+	}
+}
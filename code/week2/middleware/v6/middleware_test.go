@@ -2,20 +2,21 @@ package web
 
 import (
 	"fmt"
+	"github.com/stretchr/testify/assert"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
 // Test_Middleware 测试中间件的工作顺序
 func Test_Middleware(t *testing.T) {
-	s := NewHTTPServer()
-
-	s.middlewares = []Middleware{
+	// 中间件链在创建服务器时构建 因此需要通过Option设置中间件
+	s := NewHTTPServer(ServerWithMiddleware(
 		Middleware1,
 		Middleware2,
 		Middleware3,
 		Middleware4,
-	}
+	))
 
 	s.ServeHTTP(nil, &http.Request{})
 }
@@ -47,3 +48,100 @@ func Middleware4(next HandleFunc) HandleFunc {
 		fmt.Println("中间件4不会被执行")
 	}
 }
+
+// TestHTTPServer_Use 测试路由级中间件的执行范围与执行顺序
+func TestHTTPServer_Use(t *testing.T) {
+	// recordMiddleware 创建一个将自身名称记录到响应数据中的中间件
+	recordMiddleware := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				ctx.RespData = append(ctx.RespData, []byte(name+" ")...)
+				next(ctx)
+			}
+		}
+	}
+
+	s := NewHTTPServer()
+	s.Use("/", recordMiddleware("root"))
+	s.Use("/admin/*", recordMiddleware("auth"))
+	s.Use("/api/:version/*", recordMiddleware("limit"))
+	s.Use("/api/v1/users", recordMiddleware("users"))
+	// 作用范围重叠的中间件 参数节点与通配符节点在中间件树上可以共存 参数名也可以不同
+	s.Use("/api/*", recordMiddleware("log"))
+	s.Use("/api/:v/orders", recordMiddleware("orders"))
+	// 有子节点的通配符节点 依旧覆盖后续所有路由段
+	s.Use("/admin/*/audit", recordMiddleware("audit"))
+
+	mockHandleFunc := func(ctx *Context) {
+		ctx.RespData = append(ctx.RespData, []byte("handle")...)
+	}
+	s.GET("/admin/users/list", mockHandleFunc)
+	s.GET("/admin/:id", mockHandleFunc)
+	s.GET("/admin/:id/audit", mockHandleFunc)
+	s.GET("/api/v1/users", mockHandleFunc)
+	s.GET("/api/:version/orders", mockHandleFunc)
+	s.GET("/login", mockHandleFunc)
+
+	// 先注册路由后注册的中间件 同样需要作用于已注册的路由
+	s.Use("/login", recordMiddleware("login"))
+
+	testCases := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "wildcard covers multi segments",
+			path:     "/admin/users/list",
+			wantCode: http.StatusOK,
+			wantBody: "root auth handle",
+		},
+		{
+			name:     "wildcard covers param",
+			path:     "/admin/123",
+			wantCode: http.StatusOK,
+			wantBody: "root auth handle",
+		},
+		{
+			name:     "wildcard with children",
+			path:     "/admin/123/audit",
+			wantCode: http.StatusOK,
+			wantBody: "root auth audit handle",
+		},
+		{
+			name:     "static and param ancestors",
+			path:     "/api/v1/users",
+			wantCode: http.StatusOK,
+			wantBody: "root log limit users handle",
+		},
+		{
+			name:     "param ancestor only",
+			path:     "/api/v2/orders",
+			wantCode: http.StatusOK,
+			wantBody: "root log limit orders handle",
+		},
+		{
+			name:     "registered after route",
+			path:     "/login",
+			wantCode: http.StatusOK,
+			wantBody: "root login handle",
+		},
+		{
+			name:     "not found skips route middlewares",
+			path:     "/admin",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+		})
+	}
+}
@@ -1,10 +1,21 @@
 package err_page
 
-import "web"
+import (
+	"net/http"
+	"web"
+)
 
 // MiddlewareBuilder 错误页面中间件构造器
 type MiddlewareBuilder struct {
-	respPages map[int][]byte // respPages 用于存储响应码与其对应的错误页面 其中key为响应码 value为错误页面的内容
+	respPages     map[int][]byte // respPages 用于存储响应码与其对应的错误页面 其中key为响应码 value为错误页面的内容
+	respTemplates map[int]string // respTemplates 用于存储响应码与其对应的错误页面模板 其中key为响应码 value为模板名称
+}
+
+// PageData 渲染错误页面模板时的模板数据
+type PageData struct {
+	Status     int    // Status 响应码
+	StatusText string // StatusText 响应码对应的文本 例如 Not Found
+	Path       string // Path 请求路径
 }
 
 // Build 构造错误页面中间件
@@ -13,6 +24,18 @@ func (m *MiddlewareBuilder) Build() web.Middleware {
 		return func(ctx *web.Context) {
 			next(ctx)
 
+			// 优先使用模板渲染错误页面 渲染失败时(例如未设置模板引擎)使用 AddCode 添加的错误页面
+			if name, ok := m.respTemplates[ctx.RespStatusCode]; ok {
+				data := PageData{
+					Status:     ctx.RespStatusCode,
+					StatusText: http.StatusText(ctx.RespStatusCode),
+					Path:       ctx.Req.URL.Path,
+				}
+				if err := ctx.Render(ctx.RespStatusCode, name, data); err == nil {
+					return
+				}
+			}
+
 			// 判断响应码是否为需要篡改响应的响应码 如果是则篡改响应
 			respPage, ok := m.respPages[ctx.RespStatusCode]
 			if ok {
@@ -33,9 +56,20 @@ func (m *MiddlewareBuilder) AddCode(status int, page []byte) *MiddlewareBuilder
 	return m
 }
 
+// AddTemplate 添加响应码与其对应的错误页面模板 模板通过 web.ServerWithTemplateEngine 设置的模板引擎渲染 模板数据为 PageData
+func (m *MiddlewareBuilder) AddTemplate(status int, name string) *MiddlewareBuilder {
+	if m.respTemplates == nil {
+		m.respTemplates = make(map[int]string)
+	}
+	m.respTemplates[status] = name
+
+	return m
+}
+
 // NewMiddlewareBuilder 初始化错误页面中间件构造器
 func NewMiddlewareBuilder() *MiddlewareBuilder {
 	return &MiddlewareBuilder{
-		respPages: make(map[int][]byte),
+		respPages:     make(map[int][]byte),
+		respTemplates: make(map[int]string),
 	}
 }
@@ -1,7 +1,12 @@
 package err_page
 
 import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 	"web"
 )
 
@@ -42,3 +47,32 @@ func Test_MiddlewareBuilder(t *testing.T) {
 	// 启动服务器
 	server.Start(":8080")
 }
+
+// Test_MiddlewareBuilder_AddTemplate 测试使用模板渲染错误页面
+func Test_MiddlewareBuilder_AddTemplate(t *testing.T) {
+	engine, err := web.NewHTMLTemplateEngine(fstest.MapFS{
+		"errors/404.html": {Data: []byte(`<h1>{{.Status}} {{.StatusText}}: {{.Path}}</h1>`)},
+	})
+	require.NoError(t, err)
+
+	builder := NewMiddlewareBuilder().
+		AddTemplate(http.StatusNotFound, "errors/404.html").
+		AddTemplate(http.StatusInternalServerError, "errors/missing.html").
+		AddCode(http.StatusInternalServerError, []byte("500"))
+	server := web.NewHTTPServer(web.ServerWithMiddleware(builder.Build()), web.ServerWithTemplateEngine(engine))
+	server.GET("/panic", func(ctx *web.Context) {
+		ctx.RespStatusCode = http.StatusInternalServerError
+	})
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "<h1>404 Not Found: /missing</h1>", recorder.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	// 模板渲染失败时使用 AddCode 添加的错误页面
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "500", recorder.Body.String())
+}
@@ -1,102 +1,570 @@
 package web
 
 import (
-	"fmt"
+	"regexp"
 	"strings"
 )
 
+// nodeType 节点类型
+type nodeType int
+
+const (
+	// nodeTypeStatic 静态路由
+	nodeTypeStatic nodeType = iota
+	// nodeTypeReg 正则路由
+	nodeTypeReg
+	// nodeTypeParam 参数路由
+	nodeTypeParam
+	// nodeTypeAny 通配符路由
+	nodeTypeAny
+)
+
 // node 路由树的节点
 type node struct {
-	route         string           // route 当前节点的全路由
-	path          string           // path 当前节点的路径
-	children      map[string]*node // children 子路由路径到子节点的映射
-	wildcardChild *node            // wildcardChild 通配符子节点
-	paramChild    *node            // paramChild 参数子节点
-	HandleFunc                     // HandleFunc 路由对应的业务逻辑
+	typ             nodeType         // typ 节点类型
+	route           string           // route 当前节点的全路由
+	path            string           // path 当前节点的路径
+	paramName       string           // paramName 参数名 仅参数节点和正则节点使用 例如 :id :id(\d+) 和 :id<int> 的参数名均为 id
+	regExp          *regexp.Regexp   // regExp 编译好的正则表达式 仅正则节点使用
+	validator       ParamValidator   // validator 路径参数的校验函数 仅带约束的参数节点使用
+	children        map[string]*node // children 子路由路径到子节点的映射
+	radixChildren   *radixNode       // radixChildren 以压缩前缀树索引的静态子节点 仅在使用压缩前缀树路由时使用 此时children为nil
+	radix           bool             // radix 是否使用压缩前缀树索引静态子节点 子节点继承父节点的该属性
+	wildcardChild   *node            // wildcardChild 通配符子节点
+	paramChild      *node            // paramChild 参数子节点
+	regChild        *node            // regChild 正则子节点
+	constraintChild *node            // constraintChild 带约束的参数子节点
+	mdls            []Middleware     // mdls 注册在当前节点上的中间件 在中间件树上为路由级中间件 在路由树上为仅作用于该路由的中间件
+	matchedMdls     []Middleware     // matchedMdls 命中当前节点时需要执行的全部路由级中间件 按从根节点到叶子节点的顺序排列
+	chain           HandleFunc       // chain 使用matchedMdls包装HandleFunc后得到的处理函数 缓存起来避免每次请求时重新构建
+	HandleFunc                       // HandleFunc 路由对应的业务逻辑
 }
 
 // childOrCreate 本方法用于在节点上获取给定的子节点,如果给定的子节点不存在则创建
-func (n *node) childOrCreate(segment string) *node {
+// 正则路由的格式为 :参数名(正则表达式) 例如 :id(\d+) 正则表达式需匹配整个路由段 且不能包含"/"
+// 正则路由可以与参数路由或通配符路由共存 查找时正则路由的优先级更高
+// 带约束的参数路由的格式为 :参数名<约束> 例如 :id<int> :name<len(3,10)> 约束详见 RegisterParamConstraint
+// 带约束的参数路由同样可以与参数路由或通配符路由共存 查找时优先级低于正则路由 高于参数路由
+// 路由段不合规或与已有子节点冲突时返回 *RouteError 此时不会创建子节点
+func (n *node) childOrCreate(segment string) (*node, *RouteError) {
+	// 如果路径为正则 则查找当前节点的正则子节点 或创建一个当前节点的正则子节点 并返回
+	if isRegSegment(segment) {
+		// 若当前节点已有相同的正则子节点 则直接返回该子节点
+		if n.regChild != nil && n.regChild.path == segment {
+			return n.regChild, nil
+		}
+
+		// 若当前节点的正则子节点不为空 说明当前节点已被注册了一个不同的正则子节点 不允许再注册正则子节点
+		if n.regChild != nil {
+			return nil, newRouteError(ErrRouteConflict, n.regChild.existingRoute(), "web: 路由冲突,正则路由冲突")
+		}
+
+		paramName, expr := splitRegSegment(segment)
+		if paramName == "" {
+			return nil, newRouteError(ErrInvalidPattern, "", "web: 非法路由,正则路由缺少参数名.路由段 %s", segment)
+		}
+
+		// Tips: 正则表达式需要匹配整个路由段 而非路由段的一部分 因此需要加上首尾锚点
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, newRouteError(ErrInvalidPattern, "", "web: 非法路由,无法编译正则表达式.路由段 %s", segment)
+		}
+
+		n.regChild = &node{
+			typ:       nodeTypeReg,
+			path:      segment,
+			paramName: paramName,
+			regExp:    re,
+			radix:     n.radix,
+		}
+		return n.regChild, nil
+	}
+
+	// 如果路径为带约束的参数 则查找当前节点的带约束参数子节点 或创建一个当前节点的带约束参数子节点 并返回
+	if isConstraintSegment(segment) {
+		// 若当前节点已有相同的带约束参数子节点 则直接返回该子节点
+		if n.constraintChild != nil && n.constraintChild.path == segment {
+			return n.constraintChild, nil
+		}
+
+		// 若当前节点已被注册了一个约束不同的参数子节点 则不允许再注册 否则无法确定请求应当命中哪个路由
+		if n.constraintChild != nil {
+			return nil, newRouteError(ErrRouteConflict, n.constraintChild.existingRoute(), "web: 路由冲突,参数约束冲突")
+		}
+
+		paramName, validator, err := parseConstraintSegment(segment)
+		if err != nil {
+			routeErr := newRouteError(ErrInvalidPattern, "", "web: 非法路由,参数约束不合法.路由段 %s", segment)
+			routeErr.Cause = err
+			return nil, routeErr
+		}
+
+		n.constraintChild = &node{
+			typ:       nodeTypeParam,
+			path:      segment,
+			paramName: paramName,
+			validator: validator,
+			radix:     n.radix,
+		}
+		return n.constraintChild, nil
+	}
+
 	// 如果路径为参数 则查找当前节点的参数子节点 或创建一个当前节点的参数子节点 并返回
 	if strings.HasPrefix(segment, ":") {
 		// 若当前节点存在通配符子节点 则不允许注册参数子节点
 		if n.wildcardChild != nil {
-			panic("web: 非法路由,已有通配符路由.不允许同时注册通配符路由和参数路由")
+			return nil, newRouteError(ErrRouteConflict, n.wildcardChild.existingRoute(), "web: 路由冲突,已有通配符路由.不允许同时注册通配符路由和参数路由")
+		}
+
+		// 若当前节点已有同名的参数子节点 则直接返回该子节点 例如 /user/:id/order 与 /user/:id/detail
+		if n.paramChild != nil && n.paramChild.path == segment {
+			return n.paramChild, nil
 		}
 
 		// 若当前节点的参数子节点不为空 说明当前节点已被注册了一个参数子节点 不允许再注册参数子节点
 		if n.paramChild != nil {
-			msg := fmt.Sprintf("web: 路由冲突,参数路由冲突.已存在路由 %s", n.paramChild.path)
-			panic(msg)
+			return nil, newRouteError(ErrRouteConflict, n.paramChild.existingRoute(), "web: 路由冲突,参数路由冲突")
 		}
 
 		n.paramChild = &node{
-			path: segment,
+			typ:       nodeTypeParam,
+			path:      segment,
+			paramName: segment[1:],
+			radix:     n.radix,
 		}
-		return n.paramChild
+		return n.paramChild, nil
 	}
 
 	// 若路径为通配符 则查找当前节点的通配符子节点 或创建一个当前节点的通配符子节点 并返回
 	if segment == "*" {
 		// 若当前节点存在参数子节点 则不允许注册通配符子节点
 		if n.paramChild != nil {
-			panic("web: 非法路由,已有参数路由.不允许同时注册通配符路由和参数路由")
+			return nil, newRouteError(ErrRouteConflict, n.paramChild.existingRoute(), "web: 路由冲突,已有参数路由.不允许同时注册通配符路由和参数路由")
+		}
+
+		if n.wildcardChild == nil {
+			n.wildcardChild = &node{
+				typ:   nodeTypeAny,
+				path:  segment,
+				radix: n.radix,
+			}
 		}
+		return n.wildcardChild, nil
+	}
+
+	res, ok := n.staticChild(segment)
+	// 如果没有找到子节点,则创建一个子节点;否则返回找到的子节点
+	if !ok {
+		res = &node{
+			path:  segment,
+			radix: n.radix,
+		}
+		n.setStaticChild(segment, res)
+	}
+	return res, nil
+}
 
+// mdlChildOrCreate 在中间件树上获取给定的子节点 如果给定的子节点不存在则创建
+// 与 childOrCreate 不同 中间件树上的参数子节点与通配符子节点可以共存 参数名不同的参数路由段共用同一个参数子节点
+// 因为中间件只关心作用范围 例如 /api/* 与 /api/:version/* 分别覆盖 /api/ 下的所有路由与 /api/任意版本号/ 下的所有路由
+// 参数名也不影响中间件能覆盖哪些路由
+// 正则路由段与带约束的参数路由段的规则与 childOrCreate 相同
+func (n *node) mdlChildOrCreate(segment string) (*node, *RouteError) {
+	switch {
+	case isRegSegment(segment), isConstraintSegment(segment):
+		return n.childOrCreate(segment)
+	case strings.HasPrefix(segment, ":"):
+		if n.paramChild == nil {
+			n.paramChild = &node{
+				typ:       nodeTypeParam,
+				path:      segment,
+				paramName: segment[1:],
+			}
+		}
+		return n.paramChild, nil
+	case segment == "*":
 		if n.wildcardChild == nil {
 			n.wildcardChild = &node{
+				typ:  nodeTypeAny,
 				path: segment,
 			}
 		}
-		return n.wildcardChild
+		return n.wildcardChild, nil
+	}
+	return n.childOrCreate(segment)
+}
+
+// existingRoute 返回当前节点的子树中任意一个已注册的路由 用于在路由冲突时提示与之冲突的路由
+// 子树中没有已注册的路由时(例如中间件树上的节点) 返回当前节点的路由段
+func (n *node) existingRoute() string {
+	res := ""
+	n.walk(func(child *node) {
+		if res == "" && child.HandleFunc != nil {
+			res = "/" + child.route
+		}
+	})
+
+	if res == "" {
+		return n.path
+	}
+	return res
+}
+
+// segmentChild 查找注册时的路由段对应的子节点 与 childOrCreate 相同 但不会创建子节点
+// 与 node.match 不同 本方法的segment是已注册路由的路由段 而非请求路径中的路由段
+func (n *node) segmentChild(segment string) (*node, bool) {
+	var child *node
+	switch {
+	case isRegSegment(segment):
+		child = n.regChild
+	case isConstraintSegment(segment):
+		child = n.constraintChild
+	case strings.HasPrefix(segment, ":"):
+		child = n.paramChild
+	case segment == "*":
+		child = n.wildcardChild
+	default:
+		return n.staticChild(segment)
+	}
+	return child, child != nil && child.path == segment
+}
+
+// match 在当前节点的子树中查找给定路径(不含前导和后置的"/")对应的节点 并将路径参数追加到params中
+// 本方法是一个深度优先的回溯匹配:
+//  1. 按照 静态子节点 > 正则子节点 > 带约束的参数子节点 > 参数子节点 > 通配符子节点 的优先级依次尝试匹配当前路由段
+//     带约束的参数子节点仅在路由段满足约束时才视为匹配
+//  2. 若某个子节点匹配了当前路由段 但后续路由段匹配失败 则撤销该分支记录的路径参数 并尝试下一个子节点
+//  3. 通配符节点先只匹配1个路由段 若后续路由段匹配失败且通配符节点有业务处理函数 则匹配剩余的所有路由段 剩余路径以"*"为参数名记录
+//
+// 例如注册了 /user/detail/* 和 /user/*/create 时 /user/detail/create 能命中 /user/detail/*
+// 而注册了 /user/detail/:id/edit 和 /user/*/create 时 /user/detail/create 先尝试静态节点detail失败后 回溯命中 /user/*/create
+// needHandler为true时 仅当命中的节点有业务处理函数时才认为匹配成功
+// Tips: 整个过程不切割路径 子字符串与原字符串共享底层数组 只要params的容量足够就不会分配内存
+func (n *node) match(path string, params *Params, needHandler bool) (*node, bool) {
+	// 截取当前路由段与剩余路径 效果等同于 strings.Cut(path, "/")
+	segment, rest, last := path, "", true
+	if i := strings.IndexByte(path, '/'); i != -1 {
+		segment, rest, last = path[:i], path[i+1:], false
+	}
+
+	// 记录进入本层时路径参数的个数 分支匹配失败时回退到该位置
+	mark := params.len()
+
+	if child, ok := n.staticChild(segment); ok {
+		if target, ok := child.matchRest(rest, last, params, needHandler); ok {
+			return target, true
+		}
+	}
+
+	if n.regChild != nil && n.regChild.regExp.MatchString(segment) {
+		n.regChild.addParams(segment, params)
+		if target, ok := n.regChild.matchRest(rest, last, params, needHandler); ok {
+			return target, true
+		}
+		params.truncate(mark)
+	}
+
+	if n.constraintChild != nil && n.constraintChild.validator(segment) {
+		n.constraintChild.addParams(segment, params)
+		if target, ok := n.constraintChild.matchRest(rest, last, params, needHandler); ok {
+			return target, true
+		}
+		params.truncate(mark)
+	}
+
+	if n.paramChild != nil {
+		n.paramChild.addParams(segment, params)
+		if target, ok := n.paramChild.matchRest(rest, last, params, needHandler); ok {
+			return target, true
+		}
+		params.truncate(mark)
+	}
+
+	if n.wildcardChild == nil {
+		return nil, false
+	}
+
+	// 通配符节点先作为路由中间的通配符 只匹配1个路由段 继续匹配剩余路径
+	// 例如同时注册了 /a/* 和 /a/*/b 时 /a/x/b 命中 /a/*/b
+	if !last {
+		if target, ok := n.wildcardChild.match(rest, params, needHandler); ok {
+			return target, true
+		}
+		params.truncate(mark)
+	}
+
+	// 更深的分支匹配失败时 有业务处理函数的通配符节点作为末尾的通配符 匹配剩余的所有路由段
+	// 例如同时注册了 /a/* 和 /a/*/b 时 /a/x/y 与 /a/x/y/z 均命中 /a/*
+	if n.wildcardChild.HandleFunc != nil {
+		params.add("*", path)
+		return n.wildcardChild, true
+	}
+	return n.wildcardChild, last && !needHandler
+}
+
+// matchRest 当前节点匹配了一个路由段后 继续匹配剩余路径
+// last为true表示已经没有剩余的路由段 即当前节点就是查找的目标节点
+func (n *node) matchRest(rest string, last bool, params *Params, needHandler bool) (*node, bool) {
+	if last {
+		return n, !needHandler || n.HandleFunc != nil
+	}
+	return n.match(rest, params, needHandler)
+}
+
+// addParams 将当前节点(参数节点或正则节点)匹配给定路由段时产生的路径参数追加到params中
+// 整个路由段的值以参数名为key 正则表达式中的命名分组(形如 (?P<name>...))会产生额外的路径参数
+// 例如 :file((?P<name>\w+)\.(?P<ext>png|jpg)) 匹配 logo.png 时 会产生 file=logo.png name=logo ext=png
+func (n *node) addParams(segment string, params *Params) {
+	params.add(n.paramName, segment)
+	if n.typ != nodeTypeReg || n.regExp.NumSubexp() == 0 {
+		return
+	}
+
+	// Tips: 只有正则表达式中包含分组时才需要提取子匹配 避免不必要的内存分配
+	matches := n.regExp.FindStringSubmatch(segment)
+	for i, name := range n.regExp.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		params.add(name, matches[i])
+	}
+}
+
+// paramsNum 返回当前节点匹配路由段时产生的路径参数个数
+func (n *node) paramsNum() int {
+	switch n.typ {
+	case nodeTypeParam, nodeTypeAny:
+		// Tips: 通配符节点只有位于路由末尾时才会记录路径参数 但注册时无法确定之后是否会有子节点 因此按1个计算
+		return 1
+	case nodeTypeReg:
+		num := 1
+		for _, name := range n.regExp.SubexpNames() {
+			if name != "" {
+				num++
+			}
+		}
+		return num
+	default:
+		return 0
+	}
+}
+
+// isRegSegment 判断路由段是否为正则路由段
+// 判断依据:
+// 1. 以":"开头
+// 2. 包含"("
+// 3. 以")"结尾
+func isRegSegment(segment string) bool {
+	return strings.HasPrefix(segment, ":") && strings.Contains(segment, "(") && strings.HasSuffix(segment, ")")
+}
+
+// splitRegSegment 将正则路由段拆分为参数名与正则表达式
+// 例如 :id(\d+) 拆分为 id 与 \d+
+func splitRegSegment(segment string) (paramName string, expr string) {
+	start := strings.Index(segment, "(")
+	return segment[1:start], segment[start+1 : len(segment)-1]
+}
+
+// staticChild 查找给定路由段对应的静态子节点
+func (n *node) staticChild(segment string) (*node, bool) {
+	if n.radix {
+		if n.radixChildren == nil {
+			return nil, false
+		}
+		return n.radixChildren.get(segment)
+	}
+
+	// Tips: 从nil map中读取是安全的 因此无需判断children是否为nil
+	child, ok := n.children[segment]
+	return child, ok
+}
+
+// setStaticChild 设置给定路由段对应的静态子节点
+func (n *node) setStaticChild(segment string, child *node) {
+	if n.radix {
+		if n.radixChildren == nil {
+			n.radixChildren = &radixNode{}
+		}
+		n.radixChildren.insert(segment, child)
+		return
 	}
 
 	// 如果当前节点的子节点映射为空 则创建一个子节点映射
 	if n.children == nil {
 		n.children = map[string]*node{}
 	}
+	n.children[segment] = child
+}
 
-	res, ok := n.children[segment]
-	// 如果没有找到子节点,则创建一个子节点;否则返回找到的子节点
-	if !ok {
-		res = &node{
-			path: segment,
+// removeStaticChild 删除给定路由段对应的静态子节点
+func (n *node) removeStaticChild(segment string) {
+	if n.radix {
+		if n.radixChildren != nil {
+			n.radixChildren.remove(segment)
 		}
-		n.children[segment] = res
+		return
+	}
+
+	delete(n.children, segment)
+	// 与从未添加过静态子节点的节点保持一致
+	if len(n.children) == 0 {
+		n.children = nil
 	}
-	return res
 }
 
-// childOf 根据给定的path在当前节点的子节点映射中查找对应的子节点(即:匹配到了静态路由)
-// 若未在子节点映射中找到对应子节点 则先尝试返回当前节点的参数路由子节点(即:匹配到了参数路由)
-// 若参数路由子节点为空 则尝试返回当前节点的通配符子节点(即:匹配到了通配符路由)
-// 优先级: 静态路由 > 参数路由 > 通配符路由
-// child: 查找到的子节点
-// isParamChild: 查找到的子节点是否为参数路由子节点
-// found: 是否找到了对应的子节点
-func (n *node) childOf(path string) (child *node, isParamChild bool, found bool) {
-	// 当前节点的子节点映射为空 则有可能匹配到 参数路由子节点 或通配符子节点
-	// 此处优先查找参数路由子节点 因为参数路由子节点更具体 所以参数路由的优先级高于通配符路由
-	if n.children == nil {
-		// 如果当前节点的参数子节点不为空 则尝试返回当前节点的参数子节点
-		if n.paramChild != nil {
-			return n.paramChild, true, true
+// removeChild 删除给定的子节点
+func (n *node) removeChild(child *node) {
+	switch child {
+	case n.regChild:
+		n.regChild = nil
+	case n.constraintChild:
+		n.constraintChild = nil
+	case n.paramChild:
+		n.paramChild = nil
+	case n.wildcardChild:
+		n.wildcardChild = nil
+	default:
+		n.removeStaticChild(child.path)
+	}
+}
+
+// hasChildren 判断当前节点是否有任何类型的子节点
+func (n *node) hasChildren() bool {
+	return n.hasStaticChildren() || n.regChild != nil || n.constraintChild != nil || n.paramChild != nil || n.wildcardChild != nil
+}
+
+// clearHandler 清除当前节点上注册的路由
+func (n *node) clearHandler() {
+	n.route = ""
+	n.mdls = nil
+	n.matchedMdls = nil
+	n.chain = nil
+	n.HandleFunc = nil
+}
+
+// clone 深拷贝以当前节点为根的子树
+// 编译好的正则表达式 校验函数与中间件是只读的 因此拷贝前后的节点共用它们
+func (n *node) clone() *node {
+	res := *n
+	res.children = nil
+	res.radixChildren = nil
+	for _, child := range n.staticChildren() {
+		res.setStaticChild(child.path, child.clone())
+	}
+
+	for _, child := range []**node{&res.regChild, &res.constraintChild, &res.paramChild, &res.wildcardChild} {
+		if *child != nil {
+			*child = (*child).clone()
 		}
+	}
+	return &res
+}
 
-		// 如果当前节点的参数子节点为空 则尝试返回当前节点的通配符子节点
-		return n.wildcardChild, false, n.wildcardChild != nil
+// hasStaticChildren 判断当前节点是否有静态子节点
+func (n *node) hasStaticChildren() bool {
+	if n.radix {
+		return n.radixChildren != nil && !n.radixChildren.empty()
 	}
+	return len(n.children) != 0
+}
 
-	// 在子当前节点的节点映射中查找对应的子节点 若未找到同样尝试返回当前节点的参数子节点
-	// 若参数子节点为空 则尝试返回当前节点的通配符子节点
-	child, found = n.children[path]
-	if !found {
-		if n.paramChild != nil {
-			return n.paramChild, true, true
+// staticChildren 返回所有静态子节点 顺序不固定
+func (n *node) staticChildren() []*node {
+	if n.radix {
+		res := make([]*node, 0)
+		if n.radixChildren != nil {
+			n.radixChildren.each(func(child *node) {
+				res = append(res, child)
+			})
 		}
-		return n.wildcardChild, false, n.wildcardChild != nil
+		return res
+	}
+
+	res := make([]*node, 0, len(n.children))
+	for _, child := range n.children {
+		res = append(res, child)
+	}
+	return res
+}
+
+// mdlChildrenOf 在中间件树上查找能够覆盖给定路由段的所有子节点
+// 与 node.match 不同 本方法不是在静态 参数 通配符子节点中择一匹配 而是返回所有能够覆盖该路由段的子节点
+// 其中segment是已注册路由的路由段 而非请求路径中的路由段:
+// 1. 若segment为通配符 则只有通配符子节点能覆盖它
+// 2. 若segment为参数路由段 则参数子节点和通配符子节点能覆盖它
+// 3. 若segment为静态路由段 则同名静态子节点 能匹配它的正则子节点 参数子节点 通配符子节点均能覆盖它
+// 4. 正则子节点与带约束的参数子节点还能覆盖与其相同的路由段
+// 返回的子节点按照从宽泛到具体的顺序排列 即: 通配符子节点 > 参数子节点 > 带约束的参数子节点 > 正则子节点 > 静态子节点
+func (n *node) mdlChildrenOf(segment string) []*node {
+	res := make([]*node, 0, 4)
+	if n.wildcardChild != nil {
+		res = append(res, n.wildcardChild)
+	}
+
+	if segment == "*" {
+		return res
+	}
+
+	if n.paramChild != nil {
+		res = append(res, n.paramChild)
+	}
+
+	// 带约束的参数子节点能覆盖相同的路由段 以及满足约束的静态路由段
+	if n.constraintChild != nil && (segment == n.constraintChild.path || !strings.HasPrefix(segment, ":") && n.constraintChild.validator(segment)) {
+		res = append(res, n.constraintChild)
+	}
+
+	// 正则子节点能覆盖相同的正则路由段 以及能被正则表达式匹配的静态路由段
+	if n.regChild != nil && (segment == n.regChild.path || !strings.HasPrefix(segment, ":") && n.regChild.regExp.MatchString(segment)) {
+		res = append(res, n.regChild)
 	}
 
-	// 找到了对应的子节点 则返回该子节点
-	return child, false, found
+	if strings.HasPrefix(segment, ":") {
+		return res
+	}
+
+	if child, ok := n.staticChild(segment); ok {
+		res = append(res, child)
+	}
+	return res
+}
+
+// buildChain 使用给定的路由级中间件包装当前节点的HandleFunc 并将结果缓存到当前节点上
+func (n *node) buildChain(mdls []Middleware) {
+	n.matchedMdls = mdls
+	if n.HandleFunc == nil {
+		n.chain = nil
+		return
+	}
+
+	// 从后向前包装 确保越靠近根节点的中间件越先执行
+	root := n.HandleFunc
+	for i := len(mdls) - 1; i >= 0; i-- {
+		root = mdls[i](root)
+	}
+	n.chain = root
+}
+
+// walk 深度优先遍历以当前节点为根的子树 对每个节点执行给定的函数
+func (n *node) walk(fn func(n *node)) {
+	fn(n)
+	for _, child := range n.staticChildren() {
+		child.walk(fn)
+	}
+
+	if n.regChild != nil {
+		n.regChild.walk(fn)
+	}
+
+	if n.constraintChild != nil {
+		n.constraintChild.walk(fn)
+	}
+
+	if n.paramChild != nil {
+		n.paramChild.walk(fn)
+	}
+
+	if n.wildcardChild != nil {
+		n.wildcardChild.walk(fn)
+	}
 }
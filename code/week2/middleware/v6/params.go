@@ -0,0 +1,49 @@
+package web
+
+// Param 路径参数名值对
+type Param struct {
+	Key   string // Key 参数名
+	Value string // Value 参数值
+}
+
+// Params 路径参数
+// Tips: 一个路由上的路径参数通常只有寥寥几个 遍历切片查找的速度不逊于map 且切片可以预分配容量并随上下文复用
+// Tips: 因此此处使用切片而非map存储路径参数 避免每个请求都分配一个map
+type Params []Param
+
+// Get 获取给定参数名的参数值
+// 同名路径参数的值会被覆盖 例如`/user/:id/abc/:id`匹配`/user/123/abc/456`时 id = 456
+// 因此此处从后向前查找 返回最后一个同名参数的值
+func (ps Params) Get(key string) (value string, ok bool) {
+	for i := len(ps) - 1; i >= 0; i-- {
+		if ps[i].Key == key {
+			return ps[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// add 添加路径参数 接收者为nil时不做任何事情 用于只关心是否命中路由而不关心路径参数的查找
+func (ps *Params) add(key string, value string) {
+	if ps == nil {
+		return
+	}
+	*ps = append(*ps, Param{Key: key, Value: value})
+}
+
+// len 返回路径参数的个数 接收者为nil时返回0
+func (ps *Params) len() int {
+	if ps == nil {
+		return 0
+	}
+	return len(*ps)
+}
+
+// truncate 丢弃第n个之后的路径参数 用于路由查找回溯时撤销失败分支上记录的路径参数
+// Tips: 仅修改切片长度 保留底层数组 不会分配内存
+func (ps *Params) truncate(n int) {
+	if ps == nil {
+		return
+	}
+	*ps = (*ps)[:n]
+}
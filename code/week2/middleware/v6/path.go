@@ -0,0 +1,266 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ServerWithRedirectTrailingSlash 请求路径以"/"结尾时 重定向到去掉末尾"/"的规范路径
+// 例如 /user/ 重定向到 /user
+// 未开启时 路由查找会忽略末尾的"/" 即 /user/ 与 /user 命中同一个路由 但没有唯一的规范URL
+func ServerWithRedirectTrailingSlash() Option {
+	return func(server *HTTPServer) {
+		server.redirectTrailingSlash = true
+	}
+}
+
+// ServerWithCleanPath 请求路径中包含连续的"/" 或"."与".."路由段时 重定向到清理后的规范路径
+// 例如 //user/./detail/../home 重定向到 /user/home
+func ServerWithCleanPath() Option {
+	return func(server *HTTPServer) {
+		server.redirectCleanPath = true
+	}
+}
+
+// ServerWithCaseInsensitive 请求路径未命中路由时 忽略静态路由段的大小写再次查找 命中后重定向到注册时的大小写
+// 例如注册了 /User/Home 时 /user/home 重定向到 /User/Home
+// 参数 正则 通配符路由段的值保持不变
+func ServerWithCaseInsensitive() Option {
+	return func(server *HTTPServer) {
+		server.caseInsensitive = true
+	}
+}
+
+// ServerWithRawPath 使用转义后的原始路径(即 url.URL.EscapedPath)查找路由
+// 开启后 参数路由段中转义的"/"(即%2F)不会被当作路由段的分隔符 例如 /file/a%2Fb 匹配 /file/:name 时 name = a/b
+// 路径参数的值在命中路由后会被反转义
+// Tips: 开启后静态路由段同样按照转义后的形式匹配 因此包含需要转义的字符的静态路由段需要以转义后的形式注册
+func ServerWithRawPath() Option {
+	return func(server *HTTPServer) {
+		server.useRawPath = true
+	}
+}
+
+// requestPath 返回用于查找路由的请求路径
+func (s *HTTPServer) requestPath(req *http.Request) string {
+	if s.useRawPath {
+		return req.URL.EscapedPath()
+	}
+	return req.URL.Path
+}
+
+// unescapeParams 开启 ServerWithRawPath 时 反转义路径参数的值
+// 若某个值无法反转义 则保持原值
+func (s *HTTPServer) unescapeParams(params Params) {
+	if !s.useRawPath {
+		return
+	}
+
+	for i := range params {
+		if value, err := url.PathUnescape(params[i].Value); err == nil {
+			params[i].Value = value
+		}
+	}
+}
+
+// redirectCanonical 若开启了 ServerWithCleanPath 或 ServerWithRedirectTrailingSlash 且请求路径不是规范路径 则重定向到规范路径
+// 仅当规范路径能命中请求的HTTP动词的路由时才重定向 避免重定向到一个不存在的路由 或重定向后响应405
+// 返回是否已经重定向
+// Tips: 请求路径已经是规范路径时 本方法不会查找路由 也不会分配内存
+func (s *HTTPServer) redirectCanonical(ctx *Context, rt *router, reqPath string) bool {
+	if !s.redirectTrailingSlash && !s.redirectCleanPath {
+		return false
+	}
+
+	canonical := reqPath
+	if s.redirectCleanPath {
+		canonical = cleanPath(canonical)
+	}
+
+	if s.redirectTrailingSlash && len(canonical) > 1 && strings.HasSuffix(canonical, "/") {
+		canonical = "/" + strings.Trim(canonical, "/")
+	}
+
+	if canonical == reqPath || !rt.handles(ctx.Req.Method, canonical) {
+		return false
+	}
+
+	s.redirect(ctx, canonical)
+	return true
+}
+
+// redirectCaseInsensitive 若开启了 ServerWithCaseInsensitive 则在未命中路由时忽略大小写再次查找 命中后重定向到注册时的大小写
+// 返回是否已经重定向
+func (s *HTTPServer) redirectCaseInsensitive(ctx *Context, rt *router, reqPath string) bool {
+	if !s.caseInsensitive {
+		return false
+	}
+
+	fixed, ok := rt.foldPath(ctx.Req.Method, reqPath)
+	if !ok || fixed == reqPath {
+		return false
+	}
+
+	s.redirect(ctx, fixed)
+	return true
+}
+
+// redirect 重定向到给定路径 保留查询字符串
+// GET和HEAD请求使用301重定向 其他请求使用308重定向 以保证重定向后HTTP动词与请求体不变
+func (s *HTTPServer) redirect(ctx *Context, target string) {
+	code := http.StatusMovedPermanently
+	if ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+
+	// 开启 ServerWithRawPath 时 路径本身就是转义后的形式
+	location := target
+	if !s.useRawPath {
+		location = (&url.URL{Path: target}).EscapedPath()
+	}
+	if ctx.Req.URL.RawQuery != "" {
+		location += "?" + ctx.Req.URL.RawQuery
+	}
+
+	ctx.Resp.Header().Set("Location", location)
+	ctx.RespStatusCode = code
+}
+
+// cleanPath 清理路径中连续的"/" 以及"."与".."路由段 保留末尾的"/"
+// 例如 //user/./detail/../home/ 清理后为 /user/home/
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	if p[0] != '/' {
+		p = "/" + p
+	}
+
+	res := path.Clean(p)
+	if strings.HasSuffix(p, "/") && res != "/" {
+		res += "/"
+	}
+	return res
+}
+
+// handles 判断给定HTTP动词能否处理给定路径 HEAD请求没有注册对应的路由时使用GET请求的路由 与 HTTPServer.serve 相同
+func (r *router) handles(method string, path string) bool {
+	if _, ok := r.findHandleRoute(method, path, nil); ok {
+		return true
+	}
+	if method != http.MethodHead {
+		return false
+	}
+	_, ok := r.findHandleRoute(http.MethodGet, path, nil)
+	return ok
+}
+
+// foldPath 忽略静态路由段的大小写 在给定HTTP动词的路由树中查找给定路径 返回以注册时的大小写表示的路径
+// HEAD请求先查找HEAD请求的路由树 再查找GET请求的路由树 与 HTTPServer.serve 相同
+// Tips: 不能查找其他HTTP动词的路由树 否则重定向后的请求依旧无法命中路由
+func (r *router) foldPath(method string, reqPath string) (string, bool) {
+	trimmed := strings.Trim(reqPath, "/")
+	if trimmed == "" {
+		return "", false
+	}
+
+	methods := []string{method}
+	if method == http.MethodHead {
+		methods = append(methods, http.MethodGet)
+	}
+	for _, method := range methods {
+		root, ok := r.trees[method]
+		if !ok {
+			continue
+		}
+
+		fixed, ok := root.foldPath(trimmed)
+		if !ok {
+			continue
+		}
+
+		// 保留请求路径末尾的"/"
+		if strings.HasSuffix(reqPath, "/") {
+			fixed += "/"
+		}
+		return "/" + fixed, true
+	}
+	return "", false
+}
+
+// foldPath 在当前节点的子树中忽略静态路由段的大小写查找给定路径(不含前导和后置的"/")
+// 查找的优先级与回溯规则与 node.match 相同 大小写完全相同的静态子节点优先于仅忽略大小写后相同的静态子节点
+// 返回以注册时的大小写表示的路径 仅当命中的节点有业务处理函数时才认为找到了路由
+// Tips: 本方法仅在未命中路由时调用 因此不追求零内存分配
+func (n *node) foldPath(p string) (string, bool) {
+	segment, rest, last := p, "", true
+	if i := strings.IndexByte(p, '/'); i != -1 {
+		segment, rest, last = p[:i], p[i+1:], false
+	}
+
+	// try 当前路由段匹配了给定子节点后 继续匹配剩余路径
+	try := func(child *node, fixed string) (string, bool) {
+		if last {
+			return fixed, child.HandleFunc != nil
+		}
+
+		sub, ok := child.foldPath(rest)
+		if !ok {
+			return "", false
+		}
+		return fixed + "/" + sub, true
+	}
+
+	if child, ok := n.staticChild(segment); ok {
+		if res, ok := try(child, segment); ok {
+			return res, true
+		}
+	}
+
+	children := n.staticChildren()
+	slices.SortFunc(children, func(a, b *node) int {
+		return strings.Compare(a.path, b.path)
+	})
+	for _, child := range children {
+		if child.path == segment || !strings.EqualFold(child.path, segment) {
+			continue
+		}
+
+		if res, ok := try(child, child.path); ok {
+			return res, true
+		}
+	}
+
+	if n.regChild != nil && n.regChild.regExp.MatchString(segment) {
+		if res, ok := try(n.regChild, segment); ok {
+			return res, true
+		}
+	}
+
+	if n.constraintChild != nil && n.constraintChild.validator(segment) {
+		if res, ok := try(n.constraintChild, segment); ok {
+			return res, true
+		}
+	}
+
+	if n.paramChild != nil {
+		if res, ok := try(n.paramChild, segment); ok {
+			return res, true
+		}
+	}
+
+	if n.wildcardChild == nil {
+		return "", false
+	}
+
+	if res, ok := try(n.wildcardChild, segment); ok {
+		return res, true
+	}
+
+	// 与 node.match 相同 更深的分支匹配失败时 有业务处理函数的通配符节点匹配剩余的所有路由段
+	return p, n.wildcardChild.HandleFunc != nil
+}
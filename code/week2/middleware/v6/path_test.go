@@ -0,0 +1,280 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCleanPath 测试清理路径
+func TestCleanPath(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "empty", path: "", want: "/"},
+		{name: "root", path: "/", want: "/"},
+		{name: "clean", path: "/user/home", want: "/user/home"},
+		{name: "missing leading slash", path: "user", want: "/user"},
+		{name: "duplicate slashes", path: "//user///home", want: "/user/home"},
+		{name: "dot segments", path: "/user/./detail/../home", want: "/user/home"},
+		{name: "dot dot beyond root", path: "/../user", want: "/user"},
+		{name: "keep trailing slash", path: "/user/./home/", want: "/user/home/"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, cleanPath(testCase.path))
+		})
+	}
+}
+
+// TestHTTPServer_pathNormalization 测试路径规范化的重定向策略
+func TestHTTPServer_pathNormalization(t *testing.T) {
+	handleFunc := func(ctx *Context) {
+		ctx.RespData = []byte(ctx.MatchRoute)
+	}
+
+	newServer := func(opts ...Option) *HTTPServer {
+		s := NewHTTPServer(opts...)
+		s.GET("/user/home", handleFunc)
+		s.POST("/user/home", handleFunc)
+		s.GET("/User/Profile/:name", handleFunc)
+		s.GET("/Static/*", handleFunc)
+		s.GET("/Static/*/Min", handleFunc)
+		s.POST("/Only/Post", handleFunc)
+		return s
+	}
+
+	testCases := []struct {
+		name         string
+		server       *HTTPServer
+		method       string
+		target       string
+		wantCode     int
+		wantLocation string
+		wantBody     string
+	}{
+		{
+			name:     "trailing slash served without option",
+			server:   newServer(),
+			method:   http.MethodGet,
+			target:   "/user/home/",
+			wantCode: http.StatusOK,
+			wantBody: "user/home",
+		},
+		{
+			name:         "redirect trailing slash",
+			server:       newServer(ServerWithRedirectTrailingSlash()),
+			method:       http.MethodGet,
+			target:       "/user/home/?a=1",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/user/home?a=1",
+		},
+		{
+			name:         "redirect trailing slash with 308",
+			server:       newServer(ServerWithRedirectTrailingSlash()),
+			method:       http.MethodPost,
+			target:       "/user/home/",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "/user/home",
+		},
+		{
+			name:     "canonical path not redirected",
+			server:   newServer(ServerWithRedirectTrailingSlash(), ServerWithCleanPath(), ServerWithCaseInsensitive()),
+			method:   http.MethodGet,
+			target:   "/user/home",
+			wantCode: http.StatusOK,
+			wantBody: "user/home",
+		},
+		{
+			name:     "no redirect to missing route",
+			server:   newServer(ServerWithRedirectTrailingSlash()),
+			method:   http.MethodGet,
+			target:   "/user/detail/",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:         "clean path",
+			server:       newServer(ServerWithCleanPath()),
+			method:       http.MethodGet,
+			target:       "//user/./detail/../home",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/user/home",
+		},
+		{
+			name:         "clean path keeps trailing slash",
+			server:       newServer(ServerWithCleanPath()),
+			method:       http.MethodGet,
+			target:       "/user//home/",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/user/home/",
+		},
+		{
+			name:         "clean path and trailing slash",
+			server:       newServer(ServerWithCleanPath(), ServerWithRedirectTrailingSlash()),
+			method:       http.MethodGet,
+			target:       "/user//home/",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/user/home",
+		},
+		{
+			name:     "case sensitive without option",
+			server:   newServer(),
+			method:   http.MethodGet,
+			target:   "/USER/HOME",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:         "case insensitive",
+			server:       newServer(ServerWithCaseInsensitive()),
+			method:       http.MethodGet,
+			target:       "/USER/HOME?a=1",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/user/home?a=1",
+		},
+		{
+			name:         "case insensitive keeps param value",
+			server:       newServer(ServerWithCaseInsensitive()),
+			method:       http.MethodGet,
+			target:       "/user/profile/Tom",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/User/Profile/Tom",
+		},
+		{
+			name:         "case insensitive keeps wildcard value",
+			server:       newServer(ServerWithCaseInsensitive()),
+			method:       http.MethodGet,
+			target:       "/static/JS/App.js",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/Static/JS/App.js",
+		},
+		{
+			name:         "case insensitive wildcard with children",
+			server:       newServer(ServerWithCaseInsensitive()),
+			method:       http.MethodGet,
+			target:       "/static/JS/min",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/Static/JS/Min",
+		},
+		{
+			name:         "case insensitive with 308",
+			server:       newServer(ServerWithCaseInsensitive()),
+			method:       http.MethodPost,
+			target:       "/User/Home",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "/user/home",
+		},
+		{
+			name:     "case insensitive ignores other methods",
+			server:   newServer(ServerWithCaseInsensitive()),
+			method:   http.MethodGet,
+			target:   "/only/post",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:         "case insensitive head falls back to get",
+			server:       newServer(ServerWithCaseInsensitive()),
+			method:       http.MethodHead,
+			target:       "/USER/HOME",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/user/home",
+		},
+		{
+			name:     "clean path ignores other methods",
+			server:   newServer(ServerWithCleanPath()),
+			method:   http.MethodGet,
+			target:   "//Only/./Post",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:     "trailing slash ignores other methods",
+			server:   newServer(ServerWithRedirectTrailingSlash(), ServerWithMethodNotAllowed()),
+			method:   http.MethodGet,
+			target:   "/Only/Post/",
+			wantCode: http.StatusMethodNotAllowed,
+			wantBody: "Method Not Allowed",
+		},
+		{
+			name:         "clean path head falls back to get",
+			server:       newServer(ServerWithCleanPath()),
+			method:       http.MethodHead,
+			target:       "/user//home",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/user/home",
+		},
+		{
+			name:     "case insensitive not found",
+			server:   newServer(ServerWithCaseInsensitive()),
+			method:   http.MethodGet,
+			target:   "/user/detail",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.target, nil)
+			recorder := httptest.NewRecorder()
+			testCase.server.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantLocation, recorder.Header().Get("Location"))
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+		})
+	}
+}
+
+// TestServerWithRawPath 测试使用转义后的原始路径查找路由
+func TestServerWithRawPath(t *testing.T) {
+	handleFunc := func(ctx *Context) {
+		ctx.RespData = []byte(ctx.MatchRoute + " " + ctx.PathValue("name").value)
+	}
+
+	testCases := []struct {
+		name     string
+		opts     []Option
+		target   string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "encoded slash splits segments without option",
+			target:   "/file/a%2Fb",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:     "encoded slash preserved",
+			opts:     []Option{ServerWithRawPath()},
+			target:   "/file/a%2Fb",
+			wantCode: http.StatusOK,
+			wantBody: "file/:name a/b",
+		},
+		{
+			name:     "param value unescaped",
+			opts:     []Option{ServerWithRawPath()},
+			target:   "/file/a%20b",
+			wantCode: http.StatusOK,
+			wantBody: "file/:name a b",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			s := NewHTTPServer(testCase.opts...)
+			s.GET("/file/:name", handleFunc)
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package web
+
+// radixNode 压缩前缀树(radix tree)的节点 用于索引一个路由树节点下的所有静态子节点
+// 与以完整路由段为key的map相比 压缩前缀树中拥有公共前缀的路由段共享同一条路径
+// 例如 user users user_groups 在压缩前缀树中的结构为:
+//
+//	user (value: user)
+//	├── s (value: users)
+//	└── _groups (value: user_groups)
+//
+// Tips: 压缩前缀树只负责索引静态子节点 参数子节点与通配符子节点仍由路由树节点自身管理
+// Tips: 因此路由的优先级规则与冲突规则和使用map时完全相同
+type radixNode struct {
+	prefix  string       // prefix 当前节点相对于父节点的前缀
+	indices []byte       // indices 每个子节点前缀的首字节 与edges一一对应 用于快速选择子节点
+	edges   []*radixNode // edges 子节点 任意两个子节点前缀的首字节均不相同
+	value   *node        // value 从根节点到当前节点拼接出的字符串恰好为一个路由段时 该路由段对应的路由树节点
+}
+
+// get 查找给定路由段对应的路由树节点
+func (r *radixNode) get(key string) (*node, bool) {
+	current := r
+	for {
+		if key == "" {
+			return current.value, current.value != nil
+		}
+
+		next := current.edgeOf(key[0])
+		// Tips: 直接比较字符串切片而非使用strings.HasPrefix 语义相同
+		if next == nil || len(key) < len(next.prefix) || key[:len(next.prefix)] != next.prefix {
+			return nil, false
+		}
+
+		key = key[len(next.prefix):]
+		current = next
+	}
+}
+
+// insert 插入给定路由段与其对应的路由树节点 若路由段已存在则覆盖
+func (r *radixNode) insert(key string, value *node) {
+	current := r
+	for {
+		if key == "" {
+			current.value = value
+			return
+		}
+
+		// 没有以key首字节开头的子节点 则直接创建一个子节点
+		index := current.indexOf(key[0])
+		if index == -1 {
+			current.indices = append(current.indices, key[0])
+			current.edges = append(current.edges, &radixNode{
+				prefix: key,
+				value:  value,
+			})
+			return
+		}
+
+		// 子节点的前缀与key只有部分相同 则将子节点拆分为公共前缀与剩余部分两个节点
+		next := current.edges[index]
+		length := commonPrefixLength(key, next.prefix)
+		if length < len(next.prefix) {
+			split := &radixNode{
+				prefix:  next.prefix[:length],
+				indices: []byte{next.prefix[length]},
+				edges:   []*radixNode{next},
+			}
+			next.prefix = next.prefix[length:]
+			current.edges[index] = split
+			next = split
+		}
+
+		key = key[length:]
+		current = next
+	}
+}
+
+// remove 删除给定路由段 返回路由段是否存在
+// 删除后没有值也没有子节点的节点会被删除 没有值且只有1个子节点的节点会与其子节点合并 保证压缩前缀树始终是压缩的
+func (r *radixNode) remove(key string) bool {
+	if key == "" {
+		if r.value == nil {
+			return false
+		}
+		r.value = nil
+		return true
+	}
+
+	index := r.indexOf(key[0])
+	if index == -1 {
+		return false
+	}
+
+	next := r.edges[index]
+	if len(key) < len(next.prefix) || key[:len(next.prefix)] != next.prefix {
+		return false
+	}
+
+	if !next.remove(key[len(next.prefix):]) {
+		return false
+	}
+
+	switch {
+	case next.value == nil && len(next.edges) == 0:
+		r.indices = append(r.indices[:index], r.indices[index+1:]...)
+		r.edges = append(r.edges[:index], r.edges[index+1:]...)
+	case next.value == nil && len(next.edges) == 1:
+		child := next.edges[0]
+		child.prefix = next.prefix + child.prefix
+		r.edges[index] = child
+	}
+	return true
+}
+
+// each 遍历压缩前缀树中的所有路由树节点 顺序不固定
+func (r *radixNode) each(fn func(value *node)) {
+	if r.value != nil {
+		fn(r.value)
+	}
+
+	for _, edge := range r.edges {
+		edge.each(fn)
+	}
+}
+
+// empty 判断压缩前缀树中是否没有任何路由树节点
+func (r *radixNode) empty() bool {
+	return r.value == nil && len(r.edges) == 0
+}
+
+// indexOf 查找前缀以给定字节开头的子节点的下标 不存在时返回-1
+func (r *radixNode) indexOf(b byte) int {
+	for i, index := range r.indices {
+		if index == b {
+			return i
+		}
+	}
+	return -1
+}
+
+// edgeOf 查找前缀以给定字节开头的子节点 不存在时返回nil
+func (r *radixNode) edgeOf(b byte) *radixNode {
+	index := r.indexOf(b)
+	if index == -1 {
+		return nil
+	}
+	return r.edges[index]
+}
+
+// commonPrefixLength 计算两个字符串公共前缀的长度
+func commonPrefixLength(a string, b string) int {
+	length := min(len(a), len(b))
+	for i := 0; i < length; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return length
+}
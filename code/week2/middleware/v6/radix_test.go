@@ -0,0 +1,175 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// TestRadixNode 测试压缩前缀树的插入 拆分与查找
+func TestRadixNode(t *testing.T) {
+	keys := []string{"user", "users", "user_groups", "order", "o", "u"}
+	root := &radixNode{}
+	values := map[string]*node{}
+	for _, key := range keys {
+		values[key] = &node{path: key}
+		root.insert(key, values[key])
+	}
+
+	// 拥有公共前缀的路由段共享同一条路径
+	user := root.edgeOf('u')
+	assert.Equal(t, "u", user.prefix)
+	assert.Equal(t, values["u"], user.value)
+	ser := user.edgeOf('s')
+	assert.Equal(t, "ser", ser.prefix)
+	assert.Equal(t, values["user"], ser.value)
+	assert.Len(t, ser.edges, 2)
+
+	// 所有插入的路由段都能被查找到
+	for _, key := range keys {
+		value, ok := root.get(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, values[key], value)
+	}
+
+	// 路由段的前缀 更长的路由段 不存在的路由段均不能被查找到
+	for _, key := range []string{"", "us", "user_", "userss", "orders", "x"} {
+		_, ok := root.get(key)
+		assert.False(t, ok, key)
+	}
+
+	// 遍历能访问到所有路由树节点
+	visited := map[*node]bool{}
+	root.each(func(value *node) {
+		visited[value] = true
+	})
+	assert.Len(t, visited, len(keys))
+}
+
+// TestRouter_radix 测试压缩前缀树实现与默认实现的查找结果完全一致
+func TestRouter_radix(t *testing.T) {
+	testRoutes := []TestNode{
+		{method: http.MethodGet, path: "/"},
+		{method: http.MethodGet, path: "/user"},
+		{method: http.MethodGet, path: "/users"},
+		{method: http.MethodGet, path: "/user_groups/:id"},
+		{method: http.MethodGet, path: "/user/home"},
+		{method: http.MethodGet, path: "/order/detail"},
+		{method: http.MethodGet, path: "/order/*"},
+		{method: http.MethodGet, path: "/order/detail/:id"},
+		{method: http.MethodPost, path: "/order/create"},
+	}
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		isFound    bool
+		wantRoute  string
+		wantParams map[string]string
+	}{
+		{name: "method not found", method: http.MethodDelete, path: "/user", isFound: false},
+		{name: "root", method: http.MethodGet, path: "/", isFound: true, wantRoute: "/"},
+		{name: "shared prefix", method: http.MethodGet, path: "/user", isFound: true, wantRoute: "user"},
+		{name: "shared prefix longer", method: http.MethodGet, path: "/users", isFound: true, wantRoute: "users"},
+		{name: "prefix only", method: http.MethodGet, path: "/use", isFound: false},
+		{
+			name:       "param after shared prefix",
+			method:     http.MethodGet,
+			path:       "/user_groups/12",
+			isFound:    true,
+			wantRoute:  "user_groups/:id",
+			wantParams: map[string]string{"id": "12"},
+		},
+		{name: "static over wildcard", method: http.MethodGet, path: "/order/detail", isFound: true, wantRoute: "order/detail"},
+		{
+			name:       "wildcard",
+			method:     http.MethodGet,
+			path:       "/order/abc",
+			isFound:    true,
+			wantRoute:  "order/*",
+			wantParams: map[string]string{"*": "abc"},
+		},
+		{
+			name:       "param",
+			method:     http.MethodGet,
+			path:       "/order/detail/123",
+			isFound:    true,
+			wantRoute:  "order/detail/:id",
+			wantParams: map[string]string{"id": "123"},
+		},
+		{name: "node without handler", method: http.MethodGet, path: "/order", isFound: true, wantRoute: ""},
+		{name: "post tree", method: http.MethodPost, path: "/order/create", isFound: true, wantRoute: "order/create"},
+		{name: "not found", method: http.MethodGet, path: "/login", isFound: false},
+	}
+
+	routers := map[string]router{
+		"map":   newRouter(),
+		"radix": newRadixRouter(),
+	}
+
+	mockHandleFunc := func(ctx *Context) {}
+	for name, r := range routers {
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
+
+		for _, testCase := range testCases {
+			t.Run(name+"/"+testCase.name, func(t *testing.T) {
+				foundNode, found := r.findRoute(testCase.method, testCase.path)
+				assert.Equal(t, testCase.isFound, found)
+				if !found {
+					return
+				}
+
+				assert.Equal(t, testCase.wantRoute, foundNode.node.route)
+				assert.Equal(t, testCase.wantParams, foundNode.pathParams)
+			})
+		}
+	}
+
+	// 压缩前缀树实现不使用children
+	assert.Nil(t, routers["radix"].trees[http.MethodGet].children)
+}
+
+// TestRouter_radix_Illegal_Case 测试压缩前缀树实现与默认实现的冲突规则完全一致
+func TestRouter_radix_Illegal_Case(t *testing.T) {
+	testCases := []struct {
+		name         string
+		path         string
+		wantErr      error
+		wantExisting string
+	}{
+		{name: "duplicate route", path: "/user", wantErr: ErrRouteConflict, wantExisting: "/user"},
+		{name: "param conflict", path: "/order/detail/:name", wantErr: ErrRouteConflict, wantExisting: "/order/detail/:id"},
+		{name: "wildcard after param", path: "/order/detail/*", wantErr: ErrRouteConflict, wantExisting: "/order/detail/:id"},
+		{name: "param after wildcard", path: "/order/list/:id", wantErr: ErrRouteConflict, wantExisting: "/order/list/*"},
+		{name: "illegal pattern", path: "/order//list", wantErr: ErrInvalidPattern},
+	}
+
+	mockHandleFunc := func(ctx *Context) {}
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		r := newTestRouter()
+		r.addRoute(http.MethodGet, "/user", mockHandleFunc)
+		r.addRoute(http.MethodGet, "/order/detail/:id", mockHandleFunc)
+		r.addRoute(http.MethodGet, "/order/list/*", mockHandleFunc)
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				err := r.register(http.MethodGet, testCase.path, mockHandleFunc)
+				assert.ErrorIs(t, err, testCase.wantErr)
+
+				var routeErr *RouteError
+				if assert.ErrorAs(t, err, &routeErr) {
+					assert.Equal(t, testCase.wantExisting, routeErr.Existing)
+				}
+			})
+		}
+	})
+}
+
+// TestServerWithRadixRouter 测试通过Option切换为压缩前缀树实现
+func TestServerWithRadixRouter(t *testing.T) {
+	s := NewHTTPServer(ServerWithRadixRouter())
+	assert.True(t, s.radix)
+}
@@ -0,0 +1,172 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Route 已注册的路由 由注册路由的方法返回 用于为路由命名
+// 例如:
+//
+//	s.GET("/user/:id<int>", handleFunc).Name("user")
+//	url, err := s.URLFor("user", map[string]string{"id": "12"}, nil) // url = /user/12
+type Route struct {
+	method string      // method 路由的HTTP动词
+	path   string      // path 注册时的路由
+	host   string      // host 路由绑定的主机模式 为空时表示默认路由森林
+	server *HTTPServer // server 路由所属的HTTP服务器 路由名称统一记录在默认路由森林中
+}
+
+// namedRoute 命名路由 记录路由名称对应的HTTP动词与路由
+// Tips: 此处不直接保存路由树上的节点 而是在生成URL时根据路由重新查找节点 这样路由树被替换后依旧可以正确生成URL
+type namedRoute struct {
+	method string // method 路由的HTTP动词
+	path   string // path 注册时的路由
+	host   string // host 路由绑定的主机模式 为空时表示默认路由森林
+}
+
+// Name 为路由命名 路由名称在整个HTTP服务器内唯一 重复命名会panic
+func (r *Route) Name(name string) *Route {
+	r.server.updateRouter(func(rt *router) {
+		rt.nameRoute(name, namedRoute{method: r.method, path: r.path, host: r.host})
+	})
+	return r
+}
+
+// newRoute 创建一个已注册路由的 Route
+func (s *HTTPServer) newRoute(method string, path string) *Route {
+	return &Route{
+		method: method,
+		path:   path,
+		server: s,
+	}
+}
+
+// URLFor 根据路由名称生成URL 路由中的参数段由params中同名的值替换 query不为空时追加为查询字符串
+// 绑定到主机模式上的路由仅生成路径部分 不包含主机
+// 1. 参数路由段 :id 使用 params["id"] 替换 值不能为空也不能包含"/"
+// 2. 正则路由段 :id(\d+) 与带约束的参数路由段 :id<int> 使用 params["id"] 替换 值需满足正则表达式或约束
+// 3. 通配符路由段使用 params["*"] 替换 只有末尾的通配符允许值中包含"/"
+// 替换的值会进行转义 例如 params["name"] = "a b" 生成的路由段为 a%20b
+func (s *HTTPServer) URLFor(name string, params map[string]string, query url.Values) (string, error) {
+	return s.currentRouter().urlFor(name, params, query)
+}
+
+// nameRoute 记录路由名称
+func (r *router) nameRoute(name string, named namedRoute) {
+	if name == "" {
+		panic("web: 路由名称不能为空")
+	}
+
+	if _, ok := r.names[name]; ok {
+		panic(fmt.Sprintf("web: 路由名称冲突,重复注册路由名称 [%s]", name))
+	}
+
+	// 直接以字面量创建的路由森林没有初始化names
+	if r.names == nil {
+		r.names = map[string]namedRoute{}
+	}
+	r.names[name] = named
+}
+
+// urlFor 根据路由名称生成URL 详见 HTTPServer.URLFor
+func (r *router) urlFor(name string, params map[string]string, query url.Values) (string, error) {
+	named, ok := r.names[name]
+	if !ok {
+		return "", fmt.Errorf("web: 路由名称 [%s] 不存在", name)
+	}
+
+	rt := r
+	if named.host != "" {
+		h, ok := r.hostRouterOf(named.host)
+		if !ok {
+			return "", fmt.Errorf("web: 路由名称 [%s] 对应的主机模式 %s 不存在", name, named.host)
+		}
+		rt = h.router
+	}
+
+	target, ok := rt.trees[named.method]
+	if !ok {
+		return "", fmt.Errorf("web: 路由名称 [%s] 对应的路由 %s 不存在", name, named.path)
+	}
+
+	// 对根节点做特殊处理
+	if named.path == "/" {
+		return appendQuery("/", query), nil
+	}
+
+	builder := strings.Builder{}
+	segments := strings.Split(named.path[1:], "/")
+	for i, segment := range segments {
+		child, ok := target.segmentChild(segment)
+		if !ok {
+			return "", fmt.Errorf("web: 路由名称 [%s] 对应的路由 %s 不存在", name, named.path)
+		}
+
+		value, err := child.fill(params, i == len(segments)-1)
+		if err != nil {
+			return "", fmt.Errorf("web: 无法生成路由名称 [%s] 对应的URL: %w", name, err)
+		}
+
+		builder.WriteByte('/')
+		builder.WriteString(value)
+		target = child
+	}
+	return appendQuery(builder.String(), query), nil
+}
+
+// appendQuery 将查询参数追加到路径之后
+func appendQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// fill 使用params中的值替换当前节点对应的路由段 返回转义后的路由段
+// last为true表示当前节点是路由的最后一个路由段 此时通配符可以匹配多个路由段 详见 node.match
+func (n *node) fill(params map[string]string, last bool) (string, error) {
+	switch n.typ {
+	case nodeTypeStatic:
+		return n.path, nil
+	case nodeTypeAny:
+		value, ok := params["*"]
+		if !ok || value == "" {
+			return "", errors.New("缺少通配符参数 *")
+		}
+
+		if !last {
+			if strings.Contains(value, "/") {
+				return "", fmt.Errorf("路由中间的通配符参数 * 不能包含'/' 实际为 %s", value)
+			}
+			return url.PathEscape(value), nil
+		}
+
+		// 末尾的通配符可以匹配多个路由段 因此逐段转义
+		parts := strings.Split(value, "/")
+		for i, part := range parts {
+			parts[i] = url.PathEscape(part)
+		}
+		return strings.Join(parts, "/"), nil
+	default:
+		value, ok := params[n.paramName]
+		if !ok || value == "" {
+			return "", fmt.Errorf("缺少路径参数 %s", n.paramName)
+		}
+
+		if strings.Contains(value, "/") {
+			return "", fmt.Errorf("路径参数 %s 不能包含'/' 实际为 %s", n.paramName, value)
+		}
+
+		if n.regExp != nil && !n.regExp.MatchString(value) {
+			return "", fmt.Errorf("路径参数 %s 不匹配路由段 %s 实际为 %s", n.paramName, n.path, value)
+		}
+
+		if n.validator != nil && !n.validator(value) {
+			return "", fmt.Errorf("路径参数 %s 不满足路由段 %s 的约束 实际为 %s", n.paramName, n.path, value)
+		}
+		return url.PathEscape(value), nil
+	}
+}
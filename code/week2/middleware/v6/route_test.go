@@ -0,0 +1,190 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// TestHTTPServer_URLFor 测试根据路由名称生成URL
+func TestHTTPServer_URLFor(t *testing.T) {
+	s := NewHTTPServer()
+	mockHandleFunc := func(ctx *Context) {}
+	s.GET("/", mockHandleFunc).Name("home")
+	s.GET("/user/:id<int>", mockHandleFunc).Name("user")
+	s.POST("/user/:id<int>/order/:orderId", mockHandleFunc).Name("order")
+	s.GET("/file/:file(\\w+\\.png)", mockHandleFunc).Name("file")
+	s.GET("/search/:keyword", mockHandleFunc).Name("search")
+	s.GET("/static/*", mockHandleFunc).Name("static")
+	s.GET("/static/*/min", mockHandleFunc).Name("minified")
+	s.GET("/*/detail", mockHandleFunc).Name("detail")
+	s.Group("/api/v1").Any("/ping", mockHandleFunc).Name("ping")
+
+	testCases := []struct {
+		name      string
+		routeName string
+		params    map[string]string
+		query     url.Values
+		wantURL   string
+		wantErr   bool
+	}{
+		{
+			name:      "root",
+			routeName: "home",
+			wantURL:   "/",
+		},
+		{
+			name:      "root with query",
+			routeName: "home",
+			query:     url.Values{"page": []string{"2"}},
+			wantURL:   "/?page=2",
+		},
+		{
+			name:      "constraint",
+			routeName: "user",
+			params:    map[string]string{"id": "12"},
+			wantURL:   "/user/12",
+		},
+		{
+			name:      "constraint mismatch",
+			routeName: "user",
+			params:    map[string]string{"id": "tom"},
+			wantErr:   true,
+		},
+		{
+			name:      "many params",
+			routeName: "order",
+			params:    map[string]string{"id": "12", "orderId": "a1"},
+			query:     url.Values{"b": []string{"2"}, "a": []string{"1"}},
+			wantURL:   "/user/12/order/a1?a=1&b=2",
+		},
+		{
+			name:      "missing param",
+			routeName: "order",
+			params:    map[string]string{"id": "12"},
+			wantErr:   true,
+		},
+		{
+			name:      "regex",
+			routeName: "file",
+			params:    map[string]string{"file": "logo.png"},
+			wantURL:   "/file/logo.png",
+		},
+		{
+			name:      "regex mismatch",
+			routeName: "file",
+			params:    map[string]string{"file": "logo.jpg"},
+			wantErr:   true,
+		},
+		{
+			name:      "escape param",
+			routeName: "search",
+			params:    map[string]string{"keyword": "a b?"},
+			wantURL:   "/search/a%20b%3F",
+		},
+		{
+			name:      "param contains slash",
+			routeName: "search",
+			params:    map[string]string{"keyword": "a/b"},
+			wantErr:   true,
+		},
+		{
+			name:      "tail wildcard",
+			routeName: "static",
+			params:    map[string]string{"*": "js/app 1.js"},
+			wantURL:   "/static/js/app%201.js",
+		},
+		{
+			name:      "wildcard in middle",
+			routeName: "detail",
+			params:    map[string]string{"*": "user"},
+			wantURL:   "/user/detail",
+		},
+		{
+			name:      "wildcard with children in middle",
+			routeName: "minified",
+			params:    map[string]string{"*": "js"},
+			wantURL:   "/static/js/min",
+		},
+		{
+			name:      "wildcard in middle contains slash",
+			routeName: "detail",
+			params:    map[string]string{"*": "user/1"},
+			wantErr:   true,
+		},
+		{
+			name:      "group",
+			routeName: "ping",
+			wantURL:   "/api/v1/ping",
+		},
+		{
+			name:      "unknown name",
+			routeName: "unknown",
+			wantErr:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			u, err := s.URLFor(testCase.routeName, testCase.params, testCase.query)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.wantURL, u)
+		})
+	}
+
+	// 生成的URL能够命中对应的路由
+	u, err := s.URLFor("static", map[string]string{"*": "js/app 1.js"}, nil)
+	assert.NoError(t, err)
+	request := httptest.NewRequest(http.MethodGet, u, nil)
+	params := Params{}
+	target, ok := s.findHandleRoute(http.MethodGet, request.URL.Path, &params)
+	assert.True(t, ok)
+	assert.Equal(t, "static/*", target.route)
+	assert.Equal(t, Params{{Key: "*", Value: "js/app 1.js"}}, params)
+}
+
+// TestRoute_Name_Illegal_Case 测试非法的路由名称
+func TestRoute_Name_Illegal_Case(t *testing.T) {
+	s := NewHTTPServer()
+	mockHandleFunc := func(ctx *Context) {}
+	s.GET("/user", mockHandleFunc).Name("user")
+
+	assert.Panicsf(t, func() {
+		s.POST("/user", mockHandleFunc).Name("user")
+	}, "web: 路由名称冲突,重复注册路由名称 [user]")
+
+	assert.Panicsf(t, func() {
+		s.GET("/order", mockHandleFunc).Name("")
+	}, "web: 路由名称不能为空")
+}
+
+// TestContext_URLFor 测试在业务处理函数中根据路由名称生成重定向的URL
+func TestContext_URLFor(t *testing.T) {
+	s := NewHTTPServer()
+	s.GET("/user/:id", func(ctx *Context) {}).Name("user")
+	s.GET("/login", func(ctx *Context) {
+		u, err := ctx.URLFor("user", map[string]string{"id": "12"}, url.Values{"from": []string{"login"}})
+		if err != nil {
+			ctx.RespStatusCode = http.StatusInternalServerError
+			return
+		}
+		http.Redirect(ctx.Resp, ctx.Req, u, http.StatusFound)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/login", nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "/user/12?from=login", recorder.Header().Get("Location"))
+
+	// 未关联HTTP服务器的上下文无法生成URL
+	_, err := (&Context{}).URLFor("user", nil, nil)
+	assert.Error(t, err)
+}
@@ -1,26 +1,76 @@
 package web
 
 import (
-	"fmt"
+	"net/http"
+	"slices"
 	"strings"
 )
 
+// httpMethods 框架支持的所有HTTP动词
+var httpMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
 // router 路由森林 用于支持对路由树的操作
 type router struct {
 	// trees 路由森林 按HTTP动词组织路由树
 	// 该map中 key为HTTP动词 value为路由树的根节点
 	// 即: 每个HTTP动词对应一棵路由树 指向每棵路由树的根节点
 	trees map[string]*node
+
+	// mdlRoot 中间件树的根节点
+	// 路由级中间件与HTTP动词无关 因此所有HTTP动词共用一棵中间件树
+	// 中间件树与路由树分开存储 避免注册中间件时与路由之间产生冲突(例如 /admin/* 与 /admin/:id)
+	mdlRoot *node
+
+	// radix 是否使用压缩前缀树索引路由树节点的静态子节点
+	// 为false时 静态子节点以完整路由段为key存储在map中
+	radix bool
+
+	// maxParams 所有已注册路由中路径参数个数的最大值
+	// 用于为上下文中的路径参数预分配容量 避免查找路由时扩容
+	maxParams int
+
+	// names 路由名称到命名路由的映射 用于根据路由名称生成URL
+	// 绑定到主机模式上的路由的名称同样记录在默认路由森林中 保证路由名称在整个HTTP服务器内唯一
+	names map[string]namedRoute
+
+	// hosts 绑定到主机模式上的路由森林 按匹配优先级排列
+	hosts []*hostRouter
 }
 
 // newRouter 创建路由森林
 func newRouter() router {
 	return router{
 		trees: map[string]*node{},
+		names: map[string]namedRoute{},
+	}
+}
+
+// newRadixRouter 创建使用压缩前缀树索引静态子节点的路由森林
+func newRadixRouter() router {
+	r := newRouter()
+	r.radix = true
+	return r
+}
+
+// addRoute 注册路由到路由森林中的路由树上 路由不合规或与已注册的路由冲突时panic
+// 路由的规则详见 register
+func (r *router) addRoute(method string, path string, handleFunc HandleFunc, mdls ...Middleware) {
+	if err := r.register(method, path, handleFunc, mdls...); err != nil {
+		panic(err)
 	}
 }
 
-// AddRoute 注册路由到路由森林中的路由树上
+// register 注册路由到路由森林中的路由树上
 // 其中path为路由的路径.该路径:
 // 1. 不得为空字符串
 // 2. 必须以"/"开头
@@ -31,25 +81,17 @@ func newRouter() router {
 // - 不能在同一个位置注册不同的参数路由.例如`/user/:id`和`/user/:name`冲突
 // - 不能在同一个位置同时注册通配符路由和参数路由.例如`/user/:id`和`/user/*`冲突
 // - 同名路径参数,在路由匹配的时候,值会被覆盖.例如`/user/:id/abc/:id`,那么`/user/123/abc/456`,最终`id = 456`
-func (r *router) addRoute(method string, path string, handleFunc HandleFunc) {
-	// step1. 检测路由是否合规
-
-	// 1.1 检测路由是否为空字符串
-	if path == "" {
-		panic("web: 路由不能为空字符串")
+// mdls为仅作用于该路由的中间件(例如路由分组上的中间件) 它们在中间件树上的中间件之后执行
+// 路由不合规或与已注册的路由冲突时返回 *RouteError 此时路由森林保持不变
+func (r *router) register(method string, path string, handleFunc HandleFunc, mdls ...Middleware) error {
+	// step1. 检测HTTP动词与路由是否合规
+	if !slices.Contains(httpMethods, method) {
+		return &RouteError{Err: ErrUnsupportedMethod, Method: method, Pattern: path, Msg: "web: 不支持的HTTP动词"}
 	}
 
-	// 1.2 检测路由是否以"/"开头
-	if path[0] != '/' {
-		panic("web: 路由必须以 '/' 开头")
-	}
-
-	// 1.3 检测路由是否以"/"结尾
-	// Tips: 这个逻辑判断放在根节点的处理后边确实是可以省点代码 但是我认为那样不太好理解
-	// Tips: 我认为正常的处理流程是:先判断入参是否合规,再进行后续的逻辑处理.仅当入参合规时,才进行后续的逻辑处理
-	// Tips: 因此我把这部分逻辑判断放在根节点的处理前边
-	if path != "/" && path[len(path)-1] == '/' {
-		panic("web: 路由不能以 '/' 结尾")
+	if err := validateRoute(path); err != nil {
+		err.Method, err.Pattern = method, path
+		return err
 	}
 
 	// step2. 找到路由树
@@ -57,7 +99,8 @@ func (r *router) addRoute(method string, path string, handleFunc HandleFunc) {
 	// 如果没有找到路由树,则创建一棵路由树
 	if !ok {
 		root = &node{
-			path: "/",
+			path:  "/",
+			radix: r.radix,
 		}
 		r.trees[method] = root
 	}
@@ -66,12 +109,14 @@ func (r *router) addRoute(method string, path string, handleFunc HandleFunc) {
 	if path == "/" {
 		// 判断根节点是否路由冲突
 		if root.HandleFunc != nil {
-			panic("web: 路由冲突,重复注册路由 [/] ")
+			return &RouteError{Err: ErrRouteConflict, Method: method, Pattern: path, Existing: path, Msg: "web: 路由冲突,重复注册路由"}
 		}
 		root.HandleFunc = handleFunc
 		// 记录根节点的全路由(实际上就是"/")
 		root.route = path
-		return
+		root.mdls = mdls
+		r.rebuildChain(root)
+		return nil
 	}
 
 	// step4. 切割path
@@ -79,42 +124,200 @@ func (r *router) addRoute(method string, path string, handleFunc HandleFunc) {
 	// Tips: 以下代码是老师写的去掉前导的"/"的方式 我认为表达力有点弱 但是性能应该会好于strings.TrimLeft
 	// Tips: 以下代码会有问题,因为假如前导字符不是"/" 则不该被去掉
 	// path = path[1:]
-	path = strings.TrimLeft(path, "/")
-	segments := strings.Split(path, "/")
+	segments := strings.Split(strings.TrimLeft(path, "/"), "/")
 
 	// step3. 为路由树添加路由
 	// Tips: 此处我认为用target指代要添加路由的节点更好理解
-	target := root
+	// stack 从根节点到目标节点经过的所有节点 注册失败时用于删除本次注册过程中创建的节点
+	stack := []*node{root}
+	paramsNum := 0
 	for _, segment := range segments {
-		// 若切割后的路由段为空字符串,则说明路由中有连续的"/"
-		if segment == "" {
-			panic("web: 路由中不得包含连续的'/'")
-		}
-
 		// 如果路由树中途有节点没有创建,则创建该节点;
 		// 如果路由树中途存在子节点,则找到该子节点
-		child := target.childOrCreate(segment)
+		child, err := stack[len(stack)-1].childOrCreate(segment)
+		if err != nil {
+			r.prune(method, stack)
+			err.Method, err.Pattern = method, path
+			return err
+		}
+		paramsNum += child.paramsNum()
 		// 继续为子节点创建子节点
-		target = child
+		stack = append(stack, child)
 	}
+	target := stack[len(stack)-1]
 
 	// 判断普通节点是否路由冲突
 	if target.HandleFunc != nil {
-		panic(fmt.Sprintf("web: 路由冲突,重复注册路由 [%s] ", path))
+		return &RouteError{Err: ErrRouteConflict, Method: method, Pattern: path, Existing: path, Msg: "web: 路由冲突,重复注册路由"}
 	}
 
 	// 为目标节点设置HandleFunc
 	target.HandleFunc = handleFunc
 
+	// 记录路径参数个数的最大值
+	r.maxParams = max(r.maxParams, paramsNum)
+
 	// 记录目标节点的全路由
-	target.route = path
+	target.route = strings.TrimLeft(path, "/")
+
+	// 记录仅作用于该路由的中间件
+	target.mdls = mdls
+
+	// 计算并缓存命中该节点时需要执行的路由级中间件
+	r.rebuildChain(target)
+	return nil
+}
+
+// validateRoute 检测路由是否合规 不合规时返回 *RouteError
+func validateRoute(path string) *RouteError {
+	// 1.1 检测路由是否为空字符串
+	if path == "" {
+		return newRouteError(ErrInvalidPattern, "", "web: 非法路由,路由不能为空字符串")
+	}
+
+	// 1.2 检测路由是否以"/"开头
+	if path[0] != '/' {
+		return newRouteError(ErrInvalidPattern, "", "web: 非法路由,路由必须以 '/' 开头")
+	}
+
+	// 1.3 检测路由是否以"/"结尾
+	// Tips: 这个逻辑判断放在根节点的处理后边确实是可以省点代码 但是我认为那样不太好理解
+	// Tips: 我认为正常的处理流程是:先判断入参是否合规,再进行后续的逻辑处理.仅当入参合规时,才进行后续的逻辑处理
+	// Tips: 因此我把这部分逻辑判断放在根节点的处理前边
+	if path != "/" && path[len(path)-1] == '/' {
+		return newRouteError(ErrInvalidPattern, "", "web: 非法路由,路由不能以 '/' 结尾")
+	}
+
+	// 1.4 检测路由中是否包含连续的"/"
+	if strings.Contains(path, "//") {
+		return newRouteError(ErrInvalidPattern, "", "web: 非法路由,路由中不得包含连续的'/'")
+	}
+	return nil
+}
+
+// prune 自底向上删除stack中没有业务处理函数也没有子节点的节点 路由树为空时整棵路由树会被删除
+// stack为从根节点到某个节点经过的所有节点
+func (r *router) prune(method string, stack []*node) {
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i].HandleFunc != nil || stack[i].hasChildren() {
+			break
+		}
+		stack[i-1].removeChild(stack[i])
+	}
+
+	if root := stack[0]; root.HandleFunc == nil && !root.hasChildren() {
+		delete(r.trees, method)
+	}
+}
+
+// removeRoute 从路由森林中删除给定的路由 返回路由是否存在
+// 删除后没有业务处理函数也没有子节点的节点会被一并删除 路由树为空时整棵路由树会被删除
+// 指向该路由的路由名称同样会被删除
+func (r *router) removeRoute(method string, path string) bool {
+	// 不合规的路由必然没有被注册过
+	if validateRoute(path) != nil {
+		return false
+	}
+
+	root, ok := r.trees[method]
+	if !ok {
+		return false
+	}
+
+	// stack 从根节点到目标节点经过的所有节点 用于自底向上删除空节点
+	stack := []*node{root}
+	if path != "/" {
+		for _, segment := range strings.Split(path[1:], "/") {
+			child, ok := stack[len(stack)-1].segmentChild(segment)
+			if !ok {
+				return false
+			}
+			stack = append(stack, child)
+		}
+	}
+
+	target := stack[len(stack)-1]
+	if target.HandleFunc == nil {
+		return false
+	}
+	target.clearHandler()
+	r.prune(method, stack)
+
+	for name, named := range r.names {
+		if named.host == "" && named.method == method && named.path == path {
+			delete(r.names, name)
+		}
+	}
+	return true
+}
+
+// clone 深拷贝路由森林 用于生成只读的路由森林快照
+// Tips: 快照只用于查找路由 中间件链已经缓存在路由节点上了 因此快照不需要中间件树
+func (r *router) clone() *router {
+	res := &router{
+		trees:     make(map[string]*node, len(r.trees)),
+		radix:     r.radix,
+		maxParams: r.maxParams,
+		names:     make(map[string]namedRoute, len(r.names)),
+		hosts:     make([]*hostRouter, 0, len(r.hosts)),
+	}
+
+	for method, root := range r.trees {
+		res.trees[method] = root.clone()
+	}
+
+	for name, named := range r.names {
+		res.names[name] = named
+	}
+
+	for _, h := range r.hosts {
+		res.hosts = append(res.hosts, &hostRouter{
+			pattern: h.pattern,
+			labels:  h.labels,
+			kind:    h.kind,
+			router:  h.router.clone(),
+		})
+	}
+	return res
 }
 
 // findRoute 根据给定的HTTP方法和路由路径,在路由森林中查找对应的节点
 // 若该节点为参数路径节点,则不仅返回该节点,还返回参数名和参数值
 // 否则,仅返回该节点
+// Tips: 本方法每次调用都会分配 matchNode 和路径参数的map 处理请求时使用的是不分配内存的 lookup
 func (r *router) findRoute(method string, path string) (*matchNode, bool) {
-	targetMatchNode := &matchNode{}
+	params := Params{}
+	target, ok := r.lookup(method, path, &params)
+	if !ok {
+		return nil, false
+	}
+
+	// 如果找到了对应的节点,则返回该节点
+	// Tips: 此处有2种设计 一种是用标量表示是否找到了子节点
+	// Tips: 另一种是 return target, target.HandleFunc != nil
+	// Tips: 这种返回就表示找到了子节点且子节点必然有对应的业务处理函数
+	// 此处我倾向用第1种设计 因为方法名叫findRoute,表示是否找到节点的意思.而非表示是否找到了一个有对应的业务处理函数的节点
+	targetMatchNode := &matchNode{
+		node: target,
+	}
+	for _, param := range params {
+		targetMatchNode.addPathParams(param.Key, param.Value)
+	}
+	return targetMatchNode, true
+}
+
+// lookup 根据给定的HTTP方法和路由路径,在路由森林中查找对应的节点 并将路径参数追加到params中
+// 与 findRoute 不同 本方法逐段遍历路径 不切割路径 不创建 matchNode 也不创建map
+// 只要params的容量足够 本方法就不会分配任何内存
+// params为nil时不记录路径参数
+// 查找时若某个分支在更深的路由段上匹配失败 会回溯并尝试优先级更低的兄弟节点 详见 node.match
+func (r *router) lookup(method string, path string, params *Params) (*node, bool) {
+	return r.search(method, path, params, false)
+}
+
+// search 在给定HTTP方法的路由树中查找路由路径对应的节点
+// needHandler为true时 仅当命中的节点有业务处理函数时才认为匹配成功 否则继续回溯
+func (r *router) search(method string, path string, params *Params, needHandler bool) (*node, bool) {
 	root, ok := r.trees[method]
 	// 给定的HTTP动词在路由森林中不存在对应的路由树,则直接返回false
 	if !ok {
@@ -123,43 +326,146 @@ func (r *router) findRoute(method string, path string) (*matchNode, bool) {
 
 	// 对根节点做特殊处理
 	if path == "/" {
-		targetMatchNode.node = root
-		return targetMatchNode, true
+		return root, !needHandler || root.HandleFunc != nil
 	}
 
-	// 给定的HTTP动词在路由森林中存在对应的路由树,则在该路由树中查找对应的节点
-	// 去掉前导和后置的"/"
-	path = strings.Trim(path, "/")
-	segments := strings.Split(path, "/")
+	// 去掉前导和后置的"/" 效果等同于 strings.Trim(path, "/") 但不会分配内存
+	start, end := 0, len(path)
+	for start < end && path[start] == '/' {
+		start++
+	}
+	for end > start && path[end-1] == '/' {
+		end--
+	}
 
-	// Tips: 同样的 这里我认为用target作为变量名表现力更强
-	target := root
+	return root.match(path[start:end], params, needHandler)
+}
 
-	for _, segment := range segments {
-		child, isParamChild, found := target.childOf(segment)
-		// 如果在当前节点的子节点映射中没有找到对应的子节点,则直接返回
-		if !found {
-			return nil, false
+// findHandleRoute 与 lookup 相同 但仅当命中的节点有业务处理函数时 才认为找到了路由
+// Tips: 没有业务处理函数的节点同样会触发回溯 例如注册了 /order/* 和 /order/detail/:id 时
+// Tips: /order/detail 会命中没有业务处理函数的静态节点detail 回溯后由通配符路由 /order/* 处理
+func (r *router) findHandleRoute(method string, path string, params *Params) (*node, bool) {
+	return r.search(method, path, params, true)
+}
+
+// allowedMethods 在所有路由树中查找给定路径 返回能够处理该路径的HTTP动词 结果按字典序排列
+// 若能处理GET请求 则同样能处理HEAD请求
+// 若能处理任意一种请求 则同样能处理OPTIONS请求(未注册时会被自动应答)
+func (r *router) allowedMethods(path string) []string {
+	allow := make([]string, 0, len(r.trees)+2)
+	for method := range r.trees {
+		if _, ok := r.findHandleRoute(method, path, nil); ok {
+			allow = append(allow, method)
 		}
+	}
+
+	if len(allow) == 0 {
+		return allow
+	}
 
-		// 若当前节点为参数节点,则将参数名和参数值保存到targetMatchNode中
-		if isParamChild {
-			// 参数名是形如 :id 的格式, 因此需要去掉前导的:
-			name := child.path[1:]
-			// 参数值就是当前路由路径中的路由段
-			value := segment
-			targetMatchNode.addPathParams(name, value)
+	if slices.Contains(allow, http.MethodGet) && !slices.Contains(allow, http.MethodHead) {
+		allow = append(allow, http.MethodHead)
+	}
+
+	if !slices.Contains(allow, http.MethodOptions) {
+		allow = append(allow, http.MethodOptions)
+	}
+
+	// Tips: map的遍历顺序是随机的 因此需要排序 保证结果稳定
+	slices.Sort(allow)
+	return allow
+}
+
+// addMiddlewares 将路由级中间件注册到中间件树上
+// 其中path的规则与 addRoute 相同 例如:
+// - `/admin/*` 上的中间件会作用于所有 /admin/ 下的路由 末尾的通配符可以覆盖多段路由
+// - `/api/:version/*` 上的中间件会作用于所有 /api/任意版本号/ 下的路由
+// 中间件的作用范围可以相互重叠 例如可以同时在 `/api/*` 与 `/api/:version/*` 上注册中间件 详见 node.mdlChildOrCreate
+// 同一节点上可以多次注册中间件 中间件按注册顺序执行
+func (r *router) addMiddlewares(path string, mdls ...Middleware) {
+	if err := validateRoute(path); err != nil {
+		err.Pattern = path
+		panic(err)
+	}
+
+	if r.mdlRoot == nil {
+		r.mdlRoot = &node{
+			path: "/",
 		}
+	}
 
-		// 如果在当前节点的子节点映射中找到了对应的子节点,则继续在该子节点中查找
-		target = child
+	target := r.mdlRoot
+	if path != "/" {
+		segments := strings.Split(strings.TrimLeft(path, "/"), "/")
+		for _, segment := range segments {
+			child, err := target.mdlChildOrCreate(segment)
+			if err != nil {
+				err.Pattern = path
+				panic(err)
+			}
+			target = child
+		}
 	}
+	target.mdls = append(target.mdls, mdls...)
 
-	// 如果找到了对应的节点,则返回该节点
-	// Tips: 此处有2种设计 一种是用标量表示是否找到了子节点
-	// Tips: 另一种是 return target, target.HandleFunc != nil
-	// Tips: 这种返回就表示找到了子节点且子节点必然有对应的业务处理函数
-	// 此处我倾向用第1种设计 因为方法名叫findRoute,表示是否找到节点的意思.而非表示是否找到了一个有对应的业务处理函数的节点
-	targetMatchNode.node = target
-	return targetMatchNode, true
+	// 中间件树发生了变化 重新计算所有路由节点上缓存的中间件链
+	r.rebuildChains()
+	// 主机模式的路由森林与默认路由森林共用同一棵中间件树 因此同样需要重新计算
+	for _, h := range r.hosts {
+		h.rebuildChains()
+	}
+}
+
+// rebuildChains 重新计算所有路由节点上缓存的中间件链
+func (r *router) rebuildChains() {
+	for _, root := range r.trees {
+		root.walk(func(n *node) {
+			if n.HandleFunc != nil {
+				r.rebuildChain(n)
+			}
+		})
+	}
+}
+
+// findMdls 根据已注册路由的全路由 在中间件树上查找所有能覆盖该路由的节点 并返回这些节点上的中间件
+// 逐层查找 每一层上可能同时有多个节点(静态 参数 通配符)能覆盖对应的路由段
+// 返回的中间件按照从根节点到叶子节点的顺序排列
+// Tips: 此处使用路由而非请求路径计算中间件 因此命中同一个路由节点的请求执行的中间件一定相同
+// Tips: 这使得计算结果可以缓存在路由节点上
+func (r *router) findMdls(route string) []Middleware {
+	if r.mdlRoot == nil {
+		return nil
+	}
+
+	res := make([]Middleware, 0, len(r.mdlRoot.mdls))
+	res = append(res, r.mdlRoot.mdls...)
+	if route == "/" {
+		return res
+	}
+
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	level := []*node{r.mdlRoot}
+	for _, segment := range segments {
+		next := make([]*node, 0, len(level))
+		for _, n := range level {
+			// Tips: 通配符节点无论是否有子节点 都能覆盖后续所有路由段 它上面的中间件在其所在层已经收集过了
+			// Tips: 因此后续层只需继续匹配它的子节点(此时它作为路由中间的通配符) 与 node.match 的规则相同
+			for _, child := range n.mdlChildrenOf(segment) {
+				res = append(res, child.mdls...)
+				next = append(next, child)
+			}
+		}
+
+		if len(next) == 0 {
+			break
+		}
+		level = next
+	}
+	return res
+}
+
+// rebuildChain 重新计算并缓存命中给定路由节点时需要执行的中间件链
+// 先执行中间件树上能覆盖该路由的中间件 再执行仅作用于该路由的中间件
+func (r *router) rebuildChain(n *node) {
+	n.buildChain(append(r.findMdls(n.route), n.mdls...))
 }
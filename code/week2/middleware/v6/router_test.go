@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -16,109 +17,127 @@ type TestNode struct {
 	path   string
 }
 
+// routerConstructors 路由森林的两种实现 key为实现的名称
+var routerConstructors = map[string]func() router{
+	"map":   newRouter,
+	"radix": newRadixRouter,
+}
+
+// forEachRouter 在路由森林的两种实现上分别运行给定的测试 确保两种实现的注册与查找结果完全一致
+// Tips: 压缩前缀树实现的静态子节点不在children中 node.equal 通过 staticChild 比较静态子节点 因此同样适用于两种实现
+func forEachRouter(t *testing.T, test func(t *testing.T, newTestRouter func() router)) {
+	for name, constructor := range routerConstructors {
+		t.Run(name, func(t *testing.T) {
+			test(t, constructor)
+		})
+	}
+}
+
 // TestRouter_addRoute 测试路由注册功能的结果是否符合预期
 func TestRouter_addRoute(t *testing.T) {
-	// step1. 构造路由树
-	testRoutes := []TestNode{
-		{
-			method: http.MethodGet,
-			path:   "/",
-		},
-		{
-			method: http.MethodGet,
-			path:   "/user",
-		},
-		{
-			method: http.MethodGet,
-			path:   "/user/home",
-		},
-		{
-			method: http.MethodGet,
-			path:   "/order/detail",
-		},
-		{
-			method: http.MethodPost,
-			path:   "/order/create",
-		},
-		{
-			method: http.MethodPost,
-			path:   "/login",
-		},
-	}
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 构造路由树
+		testRoutes := []TestNode{
+			{
+				method: http.MethodGet,
+				path:   "/",
+			},
+			{
+				method: http.MethodGet,
+				path:   "/user",
+			},
+			{
+				method: http.MethodGet,
+				path:   "/user/home",
+			},
+			{
+				method: http.MethodGet,
+				path:   "/order/detail",
+			},
+			{
+				method: http.MethodPost,
+				path:   "/order/create",
+			},
+			{
+				method: http.MethodPost,
+				path:   "/login",
+			},
+		}
 
-	r := newRouter()
-	mockHandleFunc := func(ctx *Context) {}
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
 
-	for _, testRoute := range testRoutes {
-		r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
-	}
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
 
-	// step2. 验证路由树 断言二者是否相等
-	wantRouter := &router{
-		trees: map[string]*node{
-			// GET方法路由树
-			http.MethodGet: &node{
-				path: "/",
-				children: map[string]*node{
-					"user": {
-						path: "user",
-						children: map[string]*node{
-							"home": &node{
-								path:     "home",
-								children: nil,
-								// 注意路由是/user/home 因此只有最深层的节点才有handleFunc
-								// /user和/ 都是没有handleFunc的
-								HandleFunc: mockHandleFunc,
+		// step2. 验证路由树 断言二者是否相等
+		wantRouter := &router{
+			trees: map[string]*node{
+				// GET方法路由树
+				http.MethodGet: &node{
+					path: "/",
+					children: map[string]*node{
+						"user": {
+							path: "user",
+							children: map[string]*node{
+								"home": &node{
+									path:     "home",
+									children: nil,
+									// 注意路由是/user/home 因此只有最深层的节点才有handleFunc
+									// /user和/ 都是没有handleFunc的
+									HandleFunc: mockHandleFunc,
+								},
 							},
+							HandleFunc: mockHandleFunc,
 						},
-						HandleFunc: mockHandleFunc,
-					},
-					"order": &node{
-						path: "order",
-						children: map[string]*node{
-							"detail": &node{
-								path:       "detail",
-								children:   nil,
-								HandleFunc: mockHandleFunc,
+						"order": &node{
+							path: "order",
+							children: map[string]*node{
+								"detail": &node{
+									path:       "detail",
+									children:   nil,
+									HandleFunc: mockHandleFunc,
+								},
 							},
+							HandleFunc: nil,
 						},
-						HandleFunc: nil,
 					},
+					HandleFunc: mockHandleFunc,
 				},
-				HandleFunc: mockHandleFunc,
-			},
 
-			// POST方法路由树
-			http.MethodPost: {
-				path: "/",
-				children: map[string]*node{
-					"order": &node{
-						path: "order",
-						children: map[string]*node{
-							"create": &node{
-								path:       "create",
-								children:   nil,
-								HandleFunc: mockHandleFunc,
+				// POST方法路由树
+				http.MethodPost: {
+					path: "/",
+					children: map[string]*node{
+						"order": &node{
+							path: "order",
+							children: map[string]*node{
+								"create": &node{
+									path:       "create",
+									children:   nil,
+									HandleFunc: mockHandleFunc,
+								},
 							},
+							HandleFunc: nil,
+						},
+						"login": &node{
+							path:       "login",
+							children:   nil,
+							HandleFunc: mockHandleFunc,
 						},
-						HandleFunc: nil,
-					},
-					"login": &node{
-						path:       "login",
-						children:   nil,
-						HandleFunc: mockHandleFunc,
 					},
+					HandleFunc: nil,
 				},
-				HandleFunc: nil,
 			},
-		},
-	}
+		}
 
-	// HandleFunc类型是方法,方法不可比较,因此只能比较两个路由树的结构是否相等
-	// assert.Equal(t, wantRouter, r)
+		// HandleFunc类型是方法,方法不可比较,因此只能比较两个路由树的结构是否相等
+		// assert.Equal(t, wantRouter, r)
 
-	msg, ok := wantRouter.equal(&r)
-	assert.True(t, ok, msg)
+		msg, ok := wantRouter.equal(&r)
+		assert.True(t, ok, msg)
+	})
 }
 
 // equal 比较两个路由森林是否相等
@@ -170,7 +189,7 @@ func (n *node) equal(target *node) (msg string, ok bool) {
 
 	// 若两个节点的子节点数量不相等 则不相等
 	nChildrenNum := len(n.children)
-	yChildrenNum := len(target.children)
+	yChildrenNum := len(target.staticChildren())
 	if nChildrenNum != yChildrenNum {
 		return fmt.Sprintf("两个节点的子节点数量不相等,源节点的子节点数量为 %d,目标节点的子节点数量为 %d", nChildrenNum, yChildrenNum), false
 	}
@@ -206,7 +225,7 @@ func (n *node) equal(target *node) (msg string, ok bool) {
 
 	// 比对两个节点的子节点映射是否相等
 	for path, child := range n.children {
-		dstChild, ok := target.children[path]
+		dstChild, ok := target.staticChild(path)
 		// 如果源节点的子节点中 存在目标节点没有的子节点 则不相等
 		if !ok {
 			return fmt.Sprintf("目标节点的子节点中没有path为 %s 的子节点", path), false
@@ -224,492 +243,1056 @@ func (n *node) equal(target *node) (msg string, ok bool) {
 
 // TestRouter_addRoute_Illegal_Case 测试路由注册功能的非法用例
 func TestRouter_addRoute_Illegal_Case(t *testing.T) {
-	r := newRouter()
-	mockHandleFunc := func(ctx *Context) {}
-	// 为测试路由冲突 先注册路由
-	r.addRoute(http.MethodGet, "/", mockHandleFunc)
-	r.addRoute(http.MethodGet, "/user", mockHandleFunc)
-
-	// step1. 断言路由注册功能的非法用例
-	// 1.1 测试路由为空字符串
-	assert.Panicsf(t, func() {
-		r.addRoute(http.MethodGet, "", mockHandleFunc)
-	}, "web: 路由不能为空字符串")
-
-	// 1.2 测试路由不以"/"开头
-	assert.Panicsf(t, func() {
-		r.addRoute(http.MethodGet, "login", mockHandleFunc)
-	}, "web: 路由必须以 '/' 开头")
-
-	// 1.3 测试路由以"/"结尾
-	assert.Panicsf(t, func() {
-		r.addRoute(http.MethodGet, "/login/", mockHandleFunc)
-	}, "web: 路由不能以 '/' 结尾")
-
-	// 1.4 测试路由中包含连续的"/"
-	assert.Panicsf(t, func() {
-		r.addRoute(http.MethodGet, "/login///", mockHandleFunc)
-	}, "web: 路由中不得包含连续的'/'")
-
-	// 1.5 测试路由重复注册
-	// a. 根节点重复注册
-	assert.Panicsf(t, func() {
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
+		// 为测试路由冲突 先注册路由
 		r.addRoute(http.MethodGet, "/", mockHandleFunc)
-	}, "web: 路由冲突,重复注册路由 [/] ")
-
-	// b. 普通节点重复注册
-	assert.Panicsf(t, func() {
 		r.addRoute(http.MethodGet, "/user", mockHandleFunc)
-	}, "web: 路由冲突,重复注册路由 [/user] ")
+
+		// step1. 断言路由注册功能的非法用例
+		// 1.1 测试路由为空字符串
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "", mockHandleFunc)
+		}, "web: 路由不能为空字符串")
+
+		// 1.2 测试路由不以"/"开头
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "login", mockHandleFunc)
+		}, "web: 路由必须以 '/' 开头")
+
+		// 1.3 测试路由以"/"结尾
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/login/", mockHandleFunc)
+		}, "web: 路由不能以 '/' 结尾")
+
+		// 1.4 测试路由中包含连续的"/"
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/login///", mockHandleFunc)
+		}, "web: 路由中不得包含连续的'/'")
+
+		// 1.5 测试路由重复注册
+		// a. 根节点重复注册
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/", mockHandleFunc)
+		}, "web: 路由冲突,重复注册路由 [/] ")
+
+		// b. 普通节点重复注册
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/user", mockHandleFunc)
+		}, "web: 路由冲突,重复注册路由 [/user] ")
+	})
 }
 
 // TestRouter_findRoute 测试路由查找功能
 func TestRouter_findRoute(t *testing.T) {
-	// step1. 构造路由树
-	testRoutes := []TestNode{
-		// GET方法路由树
-		TestNode{
-			method: http.MethodGet,
-			path:   "/order/detail",
-		},
-		TestNode{
-			method: http.MethodGet,
-			path:   "/",
-		},
-	}
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 构造路由树
+		testRoutes := []TestNode{
+			// GET方法路由树
+			TestNode{
+				method: http.MethodGet,
+				path:   "/order/detail",
+			},
+			TestNode{
+				method: http.MethodGet,
+				path:   "/",
+			},
+		}
 
-	r := newRouter()
-	mockHandleFunc := func(ctx *Context) {}
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
 
-	for _, testRoute := range testRoutes {
-		r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
-	}
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
 
-	// step2. 构造测试用例
-	testCases := []struct {
-		name      string
-		method    string
-		path      string
-		isFound   bool
-		matchNode *matchNode
-	}{
-		// 测试HTTP动词不存在的用例
-		{
-			name:      "method not found",
-			method:    http.MethodDelete,
-			path:      "/user",
-			isFound:   false,
-			matchNode: nil,
-		},
+		// step2. 构造测试用例
+		testCases := []struct {
+			name      string
+			method    string
+			path      string
+			isFound   bool
+			matchNode *matchNode
+		}{
+			// 测试HTTP动词不存在的用例
+			{
+				name:      "method not found",
+				method:    http.MethodDelete,
+				path:      "/user",
+				isFound:   false,
+				matchNode: nil,
+			},
 
-		// 测试完全命中的用例
-		{
-			name:    "order detail",
-			method:  http.MethodGet,
-			path:    "/order/detail",
-			isFound: true,
-			matchNode: &matchNode{
-				node: &node{
-					path:       "detail",
-					children:   nil,
-					HandleFunc: mockHandleFunc,
+			// 测试完全命中的用例
+			{
+				name:    "order detail",
+				method:  http.MethodGet,
+				path:    "/order/detail",
+				isFound: true,
+				matchNode: &matchNode{
+					node: &node{
+						path:       "detail",
+						children:   nil,
+						HandleFunc: mockHandleFunc,
+					},
 				},
 			},
-		},
 
-		// 测试命中了节点但节点的HandleFunc为nil的情况
-		{
-			name:    "order",
-			method:  http.MethodGet,
-			path:    "/order",
-			isFound: true,
-			matchNode: &matchNode{
-				node: &node{
-					path: "order",
-					children: map[string]*node{
-						"detail": &node{
-							path:       "detail",
-							children:   nil,
-							HandleFunc: mockHandleFunc,
+			// 测试命中了节点但节点的HandleFunc为nil的情况
+			{
+				name:    "order",
+				method:  http.MethodGet,
+				path:    "/order",
+				isFound: true,
+				matchNode: &matchNode{
+					node: &node{
+						path: "order",
+						children: map[string]*node{
+							"detail": &node{
+								path:       "detail",
+								children:   nil,
+								HandleFunc: mockHandleFunc,
+							},
 						},
+						HandleFunc: nil,
 					},
-					HandleFunc: nil,
 				},
 			},
-		},
 
-		// 测试根节点
-		{
-			name:    "",
-			method:  http.MethodGet,
-			path:    "/",
-			isFound: true,
-			matchNode: &matchNode{
-				node: &node{
-					path: "/",
-					children: map[string]*node{
-						"order": &node{
-							path: "order",
-							children: map[string]*node{
-								"detail": &node{
-									path:       "detail",
-									children:   nil,
-									HandleFunc: mockHandleFunc,
+			// 测试根节点
+			{
+				name:    "",
+				method:  http.MethodGet,
+				path:    "/",
+				isFound: true,
+				matchNode: &matchNode{
+					node: &node{
+						path: "/",
+						children: map[string]*node{
+							"order": &node{
+								path: "order",
+								children: map[string]*node{
+									"detail": &node{
+										path:       "detail",
+										children:   nil,
+										HandleFunc: mockHandleFunc,
+									},
 								},
+								HandleFunc: nil,
 							},
-							HandleFunc: nil,
 						},
+						HandleFunc: mockHandleFunc,
 					},
-					HandleFunc: mockHandleFunc,
 				},
 			},
-		},
-
-		// 测试路由不存在的用例
-		{
-			name:      "06-stringValue not found",
-			method:    http.MethodGet,
-			path:      "/user",
-			isFound:   false,
-			matchNode: nil,
-		},
-	}
-
-	for _, testCase := range testCases {
-		t.Run(testCase.name, func(t *testing.T) {
-			foundNode, found := r.findRoute(testCase.method, testCase.path)
-			// Tips: testCase.isFound是期望的结果,而found是实际的结果
-			assert.Equal(t, testCase.isFound, found)
 
-			// 没有找到路由就不用继续比较了
-			if !found {
-				return
-			}
+			// 测试路由不存在的用例
+			{
+				name:      "06-stringValue not found",
+				method:    http.MethodGet,
+				path:      "/user",
+				isFound:   false,
+				matchNode: nil,
+			},
+		}
 
-			// 此处和之前的测试一样 不能直接用assert.Equal()比较 因为HandleFunc不可比
-			// 所以要用封装的node.equal()方法比较
-			msg, found := testCase.matchNode.node.equal(foundNode.node)
-			assert.True(t, found, msg)
-		})
-	}
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				foundNode, found := r.findRoute(testCase.method, testCase.path)
+				// Tips: testCase.isFound是期望的结果,而found是实际的结果
+				assert.Equal(t, testCase.isFound, found)
+
+				// 没有找到路由就不用继续比较了
+				if !found {
+					return
+				}
+
+				// 此处和之前的测试一样 不能直接用assert.Equal()比较 因为HandleFunc不可比
+				// 所以要用封装的node.equal()方法比较
+				msg, found := testCase.matchNode.node.equal(foundNode.node)
+				assert.True(t, found, msg)
+			})
+		}
+	})
 }
 
 // TestRouter_wildcard 测试通配符路由的注册功能
 func TestRouter_wildcard(t *testing.T) {
-	// step1. 构造路由树
-	testRoutes := []TestNode{
-		// 普通节点的通配符子节点测试用例
-		{
-			method: http.MethodGet,
-			path:   "/order/*",
-		},
-		// 根节点的通配符子节点测试用例
-		{
-			method: http.MethodGet,
-			path:   "/*",
-		},
-		// 通配符子节点的通配符子节点测试用例
-		{
-			method: http.MethodGet,
-			path:   "/*/*",
-		},
-		// 通配符子节点的普通子节点测试用例
-		{
-			method: http.MethodGet,
-			path:   "/*/*/order",
-		},
-		// 通配符子节点的普通子节点的通配符子节点
-		{
-			method: http.MethodGet,
-			path:   "/*/*/order/*",
-		},
-	}
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 构造路由树
+		testRoutes := []TestNode{
+			// 普通节点的通配符子节点测试用例
+			{
+				method: http.MethodGet,
+				path:   "/order/*",
+			},
+			// 根节点的通配符子节点测试用例
+			{
+				method: http.MethodGet,
+				path:   "/*",
+			},
+			// 通配符子节点的通配符子节点测试用例
+			{
+				method: http.MethodGet,
+				path:   "/*/*",
+			},
+			// 通配符子节点的普通子节点测试用例
+			{
+				method: http.MethodGet,
+				path:   "/*/*/order",
+			},
+			// 通配符子节点的普通子节点的通配符子节点
+			{
+				method: http.MethodGet,
+				path:   "/*/*/order/*",
+			},
+		}
 
-	r := newRouter()
-	mockHandleFunc := func(ctx *Context) {}
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
 
-	for _, testRoute := range testRoutes {
-		r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
-	}
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
 
-	// step2. 验证路由树 断言二者是否相等
-	wantRouter := &router{
-		trees: map[string]*node{
-			http.MethodGet: {
-				path: "/",
-				children: map[string]*node{
-					"order": {
-						path:     "order",
-						children: nil,
-						wildcardChild: &node{
-							path:          "*",
-							children:      nil,
-							wildcardChild: nil,
-							HandleFunc:    mockHandleFunc,
+		// step2. 验证路由树 断言二者是否相等
+		wantRouter := &router{
+			trees: map[string]*node{
+				http.MethodGet: {
+					path: "/",
+					children: map[string]*node{
+						"order": {
+							path:     "order",
+							children: nil,
+							wildcardChild: &node{
+								path:          "*",
+								children:      nil,
+								wildcardChild: nil,
+								HandleFunc:    mockHandleFunc,
+							},
+							HandleFunc: nil,
 						},
-						HandleFunc: nil,
 					},
-				},
-				wildcardChild: &node{
-					path:     "*",
-					children: nil,
 					wildcardChild: &node{
-						path: "*",
-						children: map[string]*node{
-							"order": {
-								path:     "order",
-								children: nil,
-								wildcardChild: &node{
-									path:          "*",
-									children:      nil,
-									wildcardChild: nil,
-									HandleFunc:    mockHandleFunc,
+						path:     "*",
+						children: nil,
+						wildcardChild: &node{
+							path: "*",
+							children: map[string]*node{
+								"order": {
+									path:     "order",
+									children: nil,
+									wildcardChild: &node{
+										path:          "*",
+										children:      nil,
+										wildcardChild: nil,
+										HandleFunc:    mockHandleFunc,
+									},
+									HandleFunc: mockHandleFunc,
 								},
-								HandleFunc: mockHandleFunc,
 							},
+							wildcardChild: nil,
+							HandleFunc:    mockHandleFunc,
 						},
-						wildcardChild: nil,
-						HandleFunc:    mockHandleFunc,
+						HandleFunc: mockHandleFunc,
 					},
-					HandleFunc: mockHandleFunc,
+					HandleFunc: nil,
 				},
-				HandleFunc: nil,
 			},
-		},
-	}
+		}
 
-	msg, ok := wantRouter.equal(&r)
-	assert.True(t, ok, msg)
+		msg, ok := wantRouter.equal(&r)
+		assert.True(t, ok, msg)
+	})
 }
 
 // TestRouter_findRoute_wildcard 测试针对通配符路由的查找功能
 func TestRouter_findRoute_wildcard(t *testing.T) {
-	// step1. 构造路由树
-	testRoutes := []TestNode{
-		{
-			method: http.MethodGet,
-			path:   "/order/*",
-		},
-		{
-			method: http.MethodGet,
-			path:   "/order/detail",
-		},
-	}
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 构造路由树
+		testRoutes := []TestNode{
+			{
+				method: http.MethodGet,
+				path:   "/order/*",
+			},
+			{
+				method: http.MethodGet,
+				path:   "/order/detail",
+			},
+		}
 
-	r := newRouter()
-	mockHandleFunc := func(ctx *Context) {}
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
 
-	for _, testRoute := range testRoutes {
-		r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
-	}
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
 
-	// step2. 构造测试用例
-	testCases := []struct {
-		name      string
-		method    string
-		path      string
-		isFound   bool
-		matchNode *matchNode
-	}{
-		// 普通节点的通配符子节点测试用例
-		{
-			name:    "order wildcard",
-			method:  http.MethodGet,
-			path:    "/order/abc",
-			isFound: true,
-			matchNode: &matchNode{
-				node: &node{
-					path:          "*",
-					children:      nil,
-					wildcardChild: nil,
-					HandleFunc:    mockHandleFunc,
+		// step2. 构造测试用例
+		testCases := []struct {
+			name      string
+			method    string
+			path      string
+			isFound   bool
+			matchNode *matchNode
+		}{
+			// 普通节点的通配符子节点测试用例
+			{
+				name:    "order wildcard",
+				method:  http.MethodGet,
+				path:    "/order/abc",
+				isFound: true,
+				matchNode: &matchNode{
+					node: &node{
+						path:          "*",
+						children:      nil,
+						wildcardChild: nil,
+						HandleFunc:    mockHandleFunc,
+					},
 				},
 			},
-		},
-		// 普通节点下普通子节点和通配符子节点共存的测试用例
-		{
-			name:    "order detail",
-			method:  http.MethodGet,
-			path:    "/order/detail",
-			isFound: true,
-			matchNode: &matchNode{
-				node: &node{
-					path:          "detail",
-					children:      nil,
-					wildcardChild: nil,
-					HandleFunc:    mockHandleFunc,
+			// 普通节点下普通子节点和通配符子节点共存的测试用例
+			{
+				name:    "order detail",
+				method:  http.MethodGet,
+				path:    "/order/detail",
+				isFound: true,
+				matchNode: &matchNode{
+					node: &node{
+						path:          "detail",
+						children:      nil,
+						wildcardChild: nil,
+						HandleFunc:    mockHandleFunc,
+					},
 				},
 			},
-		},
-	}
+		}
 
-	for _, testCase := range testCases {
-		t.Run(testCase.name, func(t *testing.T) {
-			foundNode, found := r.findRoute(testCase.method, testCase.path)
-			assert.Equal(t, testCase.isFound, found)
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				foundNode, found := r.findRoute(testCase.method, testCase.path)
+				assert.Equal(t, testCase.isFound, found)
 
-			if !found {
-				return
-			}
+				if !found {
+					return
+				}
 
-			msg, found := testCase.matchNode.node.equal(foundNode.node)
-			assert.True(t, found, msg)
-		})
-	}
+				msg, found := testCase.matchNode.node.equal(foundNode.node)
+				assert.True(t, found, msg)
+			})
+		}
+	})
 }
 
 // TestRouter_addParamRoute 测试注册参数路由的结果是否符合预期
 func TestRouter_addParamRoute(t *testing.T) {
-	// step1. 构造路由树
-	testRoutes := []TestNode{
-		{
-			method: http.MethodGet,
-			path:   "/order/detail/:id",
-		},
-	}
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 构造路由树
+		testRoutes := []TestNode{
+			{
+				method: http.MethodGet,
+				path:   "/order/detail/:id",
+			},
+		}
 
-	r := newRouter()
-	mockHandleFunc := func(ctx *Context) {}
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
 
-	for _, testRoute := range testRoutes {
-		r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
-	}
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
 
-	// step2. 验证路由树 断言二者是否相等
-	wantRouter := &router{
-		trees: map[string]*node{
-			http.MethodGet: {
-				path: "/",
-				children: map[string]*node{
-					"order": {
-						path: "order",
-						children: map[string]*node{
-							"detail": {
-								path:          "detail",
-								children:      nil,
-								wildcardChild: nil,
-								paramChild: &node{
-									path:          ":id",
+		// step2. 验证路由树 断言二者是否相等
+		wantRouter := &router{
+			trees: map[string]*node{
+				http.MethodGet: {
+					path: "/",
+					children: map[string]*node{
+						"order": {
+							path: "order",
+							children: map[string]*node{
+								"detail": {
+									path:          "detail",
 									children:      nil,
 									wildcardChild: nil,
-									paramChild:    nil,
-									HandleFunc:    mockHandleFunc,
+									paramChild: &node{
+										path:          ":id",
+										children:      nil,
+										wildcardChild: nil,
+										paramChild:    nil,
+										HandleFunc:    mockHandleFunc,
+									},
+									HandleFunc: nil,
 								},
-								HandleFunc: nil,
 							},
+							wildcardChild: nil,
+							paramChild:    nil,
+							HandleFunc:    nil,
 						},
-						wildcardChild: nil,
-						paramChild:    nil,
-						HandleFunc:    nil,
 					},
+					wildcardChild: nil,
+					paramChild:    nil,
+					HandleFunc:    nil,
 				},
-				wildcardChild: nil,
-				paramChild:    nil,
-				HandleFunc:    nil,
 			},
-		},
-	}
+		}
 
-	msg, ok := wantRouter.equal(&r)
-	assert.True(t, ok, msg)
+		msg, ok := wantRouter.equal(&r)
+		assert.True(t, ok, msg)
+	})
 }
 
 // TestRouter_findRoute_param 测试针对参数路由的查找功能
 func TestRouter_findRoute_param(t *testing.T) {
-	// step1. 构造路由树
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 构造路由树
+		testRoutes := []TestNode{
+			{
+				method: http.MethodGet,
+				path:   "/order/detail/:id",
+			},
+		}
+
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
+
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
+
+		// step2. 构造测试用例
+		testCases := []struct {
+			name      string
+			method    string
+			path      string
+			isFound   bool
+			matchNode *matchNode
+		}{
+			// 普通节点的参数路由子节点测试用例
+			{
+				name:    "order detail id",
+				method:  http.MethodGet,
+				path:    "/order/detail/123",
+				isFound: true,
+				matchNode: &matchNode{
+					node: &node{
+						path:          ":id",
+						children:      nil,
+						wildcardChild: nil,
+						paramChild:    nil,
+						HandleFunc:    mockHandleFunc,
+					},
+					pathParams: map[string]string{
+						"id": "123",
+					},
+				},
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				foundNode, found := r.findRoute(testCase.method, testCase.path)
+				assert.Equal(t, testCase.isFound, found)
+
+				if !found {
+					return
+				}
+
+				msg, found := testCase.matchNode.node.equal(foundNode.node)
+				assert.True(t, found, msg)
+			})
+		}
+	})
+}
+
+// TestRouter_findRoute_param_and_wildcard_coexist 测试针对注册参数路由时,已有通配符路由的情况
+func TestRouter_findRoute_param_and_wildcard_coexist(t *testing.T) {
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 注册有冲突的路由
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
+		r.addRoute(http.MethodGet, "/order/detail/*", mockHandleFunc)
+
+		// step2. 断言非法用例
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/order/detail/:id", mockHandleFunc)
+		}, "web: 非法路由,已有通配符路由.不允许同时注册通配符路由和参数路由")
+	})
+}
+
+// TestRouter_findRoute_wildcard_and_param_coexist 测试针对注册通配符路由时,已有参数路由的情况
+func TestRouter_findRoute_wildcard_and_param_coexist(t *testing.T) {
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 注册有冲突的路由
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
+		r.addRoute(http.MethodGet, "/order/detail/:id", mockHandleFunc)
+
+		// step2. 断言非法用例
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/order/detail/*", mockHandleFunc)
+		}, "web: 非法路由,已有参数路由.不允许同时注册通配符路由和参数路由")
+	})
+}
+
+// TestRouter_findRoute_same_param_coexist 测试针对参数路由时,已有同名参数路由的情况
+func TestRouter_findRoute_same_param_coexist(t *testing.T) {
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		// step1. 注册有冲突的路由
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
+		r.addRoute(http.MethodGet, "/order/detail/:id", mockHandleFunc)
+
+		// step2. 断言非法用例
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/order/detail/:name", mockHandleFunc)
+		}, "web: 路由冲突,参数路由冲突.已存在路由 id")
+	})
+}
+
+// TestRouter_findRoute_regex 测试正则路由的查找 以及静态路由 > 正则路由 > 参数路由 > 通配符路由的优先级
+func TestRouter_findRoute_regex(t *testing.T) {
 	testRoutes := []TestNode{
+		{method: http.MethodGet, path: "/user/:id(\\d+)"},
+		{method: http.MethodGet, path: "/user/:name"},
+		{method: http.MethodGet, path: "/user/home"},
+		{method: http.MethodGet, path: "/user/:id(\\d+)/detail"},
+		{method: http.MethodGet, path: "/file/:file((?P<name>\\w+)\\.(?P<ext>png|jpg))"},
+		{method: http.MethodGet, path: "/file/*"},
+		{method: http.MethodGet, path: "/order/:id([a-z]+)/items/:item(\\d+)"},
+	}
+
+	testCases := []struct {
+		name       string
+		path       string
+		isFound    bool
+		wantRoute  string
+		wantParams map[string]string
+	}{
+		{
+			name:      "static over regex",
+			path:      "/user/home",
+			isFound:   true,
+			wantRoute: "user/home",
+		},
+		{
+			name:       "regex over param",
+			path:       "/user/123",
+			isFound:    true,
+			wantRoute:  "user/:id(\\d+)",
+			wantParams: map[string]string{"id": "123"},
+		},
+		{
+			name:       "regex mismatch fallback to param",
+			path:       "/user/tom",
+			isFound:    true,
+			wantRoute:  "user/:name",
+			wantParams: map[string]string{"name": "tom"},
+		},
 		{
-			method: http.MethodGet,
-			path:   "/order/detail/:id",
+			name:       "regex must match whole segment",
+			path:       "/user/123abc",
+			isFound:    true,
+			wantRoute:  "user/:name",
+			wantParams: map[string]string{"name": "123abc"},
+		},
+		{
+			name:       "regex child",
+			path:       "/user/123/detail",
+			isFound:    true,
+			wantRoute:  "user/:id(\\d+)/detail",
+			wantParams: map[string]string{"id": "123"},
+		},
+		{
+			name:       "named submatch",
+			path:       "/file/logo.png",
+			isFound:    true,
+			wantRoute:  "file/:file((?P<name>\\w+)\\.(?P<ext>png|jpg))",
+			wantParams: map[string]string{"file": "logo.png", "name": "logo", "ext": "png"},
+		},
+		{
+			name:       "regex over wildcard",
+			path:       "/file/logo.gif",
+			isFound:    true,
+			wantRoute:  "file/*",
+			wantParams: map[string]string{"*": "logo.gif"},
+		},
+		{
+			name:       "many regex",
+			path:       "/order/abc/items/12",
+			isFound:    true,
+			wantRoute:  "order/:id([a-z]+)/items/:item(\\d+)",
+			wantParams: map[string]string{"id": "abc", "item": "12"},
+		},
+		{
+			name:    "regex mismatch",
+			path:    "/order/123/items/12",
+			isFound: false,
 		},
 	}
 
-	r := newRouter()
 	mockHandleFunc := func(ctx *Context) {}
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		r := newTestRouter()
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
+		assert.Equal(t, 3, r.maxParams)
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				foundNode, found := r.findRoute(http.MethodGet, testCase.path)
+				assert.Equal(t, testCase.isFound, found)
+				if !found {
+					return
+				}
+
+				assert.Equal(t, testCase.wantRoute, foundNode.node.route)
+				assert.Equal(t, testCase.wantParams, foundNode.pathParams)
+			})
+		}
+	})
+}
+
+// TestRouter_addRoute_regex_Illegal_Case 测试注册非法的正则路由
+func TestRouter_addRoute_regex_Illegal_Case(t *testing.T) {
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
+		r.addRoute(http.MethodGet, "/user/:id(\\d+)", mockHandleFunc)
+
+		// 相同的正则路由段共用同一个节点
+		assert.NotPanics(t, func() {
+			r.addRoute(http.MethodGet, "/user/:id(\\d+)/detail", mockHandleFunc)
+		})
+
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/user/:id([a-z]+)", mockHandleFunc)
+		}, "web: 路由冲突,正则路由冲突.已存在路由 :id(\\d+)")
+
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/order/:(\\d+)", mockHandleFunc)
+		}, "web: 非法路由,正则路由缺少参数名.路由段 :(\\d+)")
+
+		assert.Panicsf(t, func() {
+			r.addRoute(http.MethodGet, "/order/:id([a-z)", mockHandleFunc)
+		}, "web: 非法路由,无法编译正则表达式.路由段 :id([a-z)")
+	})
+}
 
-	for _, testRoute := range testRoutes {
-		r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+// TestRouter_findRoute_backtracking 测试查找路由时 更深的路由段匹配失败后回溯到优先级更低的兄弟节点
+// 以及末尾的通配符匹配多段路由的情况
+func TestRouter_findRoute_backtracking(t *testing.T) {
+	testRoutes := []TestNode{
+		{method: http.MethodGet, path: "/user/detail/*"},
+		{method: http.MethodGet, path: "/user/*/create"},
+		{method: http.MethodGet, path: "/*/order/show"},
+		{method: http.MethodGet, path: "/cart/:id/edit"},
+		{method: http.MethodGet, path: "/cart/:id(\\d+)/view"},
+		{method: http.MethodGet, path: "/cart/list/items"},
+		{method: http.MethodGet, path: "/shop/:id(\\d+)/view"},
+		{method: http.MethodGet, path: "/shop/list/items"},
+		{method: http.MethodGet, path: "/shop/*"},
+		{method: http.MethodGet, path: "/blog/:year/:month"},
+		{method: http.MethodGet, path: "/blog/:year/latest/:tag"},
+		{method: http.MethodGet, path: "/a/*"},
+		{method: http.MethodGet, path: "/a/*/b"},
 	}
 
-	// step2. 构造测试用例
 	testCases := []struct {
-		name      string
-		method    string
-		path      string
-		isFound   bool
-		matchNode *matchNode
+		name       string
+		path       string
+		isFound    bool
+		wantRoute  string
+		wantParams map[string]string
 	}{
-		// 普通节点的参数路由子节点测试用例
-		{
-			name:    "order detail id",
-			method:  http.MethodGet,
-			path:    "/order/detail/123",
-			isFound: true,
-			matchNode: &matchNode{
-				node: &node{
-					path:          ":id",
-					children:      nil,
-					wildcardChild: nil,
-					paramChild:    nil,
-					HandleFunc:    mockHandleFunc,
+		{
+			name:       "static over wildcard",
+			path:       "/user/detail/create",
+			isFound:    true,
+			wantRoute:  "user/detail/*",
+			wantParams: map[string]string{"*": "create"},
+		},
+		{
+			name:       "multi stage wildcard",
+			path:       "/user/detail/id/login",
+			isFound:    true,
+			wantRoute:  "user/detail/*",
+			wantParams: map[string]string{"*": "id/login"},
+		},
+		{
+			name:      "wildcard in middle",
+			path:      "/user/id/create",
+			isFound:   true,
+			wantRoute: "user/*/create",
+		},
+		{
+			name:    "wildcard in middle matches one segment",
+			path:    "/user/login/id/create",
+			isFound: false,
+		},
+		{
+			name:      "wildcard in head",
+			path:      "/employee/order/show",
+			isFound:   true,
+			wantRoute: "*/order/show",
+		},
+		{
+			name:      "backtrack from static to wildcard in head",
+			path:      "/user/order/show",
+			isFound:   true,
+			wantRoute: "*/order/show",
+		},
+		{
+			name:       "backtrack from static to param",
+			path:       "/cart/list/edit",
+			isFound:    true,
+			wantRoute:  "cart/:id/edit",
+			wantParams: map[string]string{"id": "list"},
+		},
+		{
+			name:       "backtrack from regex to param",
+			path:       "/cart/123/edit",
+			isFound:    true,
+			wantRoute:  "cart/:id/edit",
+			wantParams: map[string]string{"id": "123"},
+		},
+		{
+			name:       "regex over param",
+			path:       "/cart/123/view",
+			isFound:    true,
+			wantRoute:  "cart/:id(\\d+)/view",
+			wantParams: map[string]string{"id": "123"},
+		},
+		{
+			name:    "param without matching child",
+			path:    "/cart/abc/view",
+			isFound: false,
+		},
+		{
+			name:       "backtrack from regex to tail wildcard",
+			path:       "/shop/123/edit",
+			isFound:    true,
+			wantRoute:  "shop/*",
+			wantParams: map[string]string{"*": "123/edit"},
+		},
+		{
+			name:       "backtrack from static to tail wildcard",
+			path:       "/shop/list/items/1",
+			isFound:    true,
+			wantRoute:  "shop/*",
+			wantParams: map[string]string{"*": "list/items/1"},
+		},
+		{
+			name:       "many params",
+			path:       "/blog/2024/01",
+			isFound:    true,
+			wantRoute:  "blog/:year/:month",
+			wantParams: map[string]string{"year": "2024", "month": "01"},
+		},
+		{
+			name:       "more specific route preferred",
+			path:       "/blog/2024/latest/go",
+			isFound:    true,
+			wantRoute:  "blog/:year/latest/:tag",
+			wantParams: map[string]string{"year": "2024", "tag": "go"},
+		},
+		{
+			name:    "not found",
+			path:    "/blog/2024/01/02",
+			isFound: false,
+		},
+		{
+			name:      "wildcard with children in middle",
+			path:      "/a/x/b",
+			isFound:   true,
+			wantRoute: "a/*/b",
+		},
+		{
+			name:       "wildcard with children matches one segment",
+			path:       "/a/x",
+			isFound:    true,
+			wantRoute:  "a/*",
+			wantParams: map[string]string{"*": "x"},
+		},
+		{
+			name:       "backtrack from children to wildcard",
+			path:       "/a/x/y",
+			isFound:    true,
+			wantRoute:  "a/*",
+			wantParams: map[string]string{"*": "x/y"},
+		},
+		{
+			name:       "backtrack from deeper children to wildcard",
+			path:       "/a/x/y/z",
+			isFound:    true,
+			wantRoute:  "a/*",
+			wantParams: map[string]string{"*": "x/y/z"},
+		},
+		{
+			name:       "backtrack from child of children to wildcard",
+			path:       "/a/x/b/c",
+			isFound:    true,
+			wantRoute:  "a/*",
+			wantParams: map[string]string{"*": "x/b/c"},
+		},
+	}
+
+	mockHandleFunc := func(ctx *Context) {}
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		r := newTestRouter()
+		for _, testRoute := range testRoutes {
+			r.addRoute(testRoute.method, testRoute.path, mockHandleFunc)
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				foundNode, found := r.findRoute(http.MethodGet, testCase.path)
+				assert.Equal(t, testCase.isFound, found)
+				if !found {
+					return
+				}
+
+				assert.Equal(t, testCase.wantRoute, foundNode.node.route)
+				assert.Equal(t, testCase.wantParams, foundNode.pathParams)
+			})
+		}
+	})
+}
+
+// TestRouter_findHandleRoute_backtracking 测试命中没有业务处理函数的节点时 同样会回溯
+func TestRouter_findHandleRoute_backtracking(t *testing.T) {
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
+		r.addRoute(http.MethodGet, "/order/*", mockHandleFunc)
+		r.addRoute(http.MethodGet, "/order/detail/:id", mockHandleFunc)
+
+		// lookup 不关心节点是否有业务处理函数
+		target, found := r.lookup(http.MethodGet, "/order/detail", nil)
+		assert.True(t, found)
+		assert.Equal(t, "", target.route)
+
+		params := Params{}
+		target, found = r.findHandleRoute(http.MethodGet, "/order/detail", &params)
+		assert.True(t, found)
+		assert.Equal(t, "order/*", target.route)
+		assert.Equal(t, Params{{Key: "*", Value: "detail"}}, params)
+
+		// 回溯时失败分支上记录的路径参数被丢弃
+		r.addRoute(http.MethodGet, "/blog/:year(\\d+)/latest/:tag", mockHandleFunc)
+		r.addRoute(http.MethodGet, "/blog/:name/latest", mockHandleFunc)
+		params = Params{}
+		target, found = r.findHandleRoute(http.MethodGet, "/blog/2024/latest", &params)
+		assert.True(t, found)
+		assert.Equal(t, "blog/:name/latest", target.route)
+		assert.Equal(t, Params{{Key: "name", Value: "2024"}}, params)
+	})
+}
+
+// TestRouter_lookup 测试不分配内存的路由查找功能 以及路径参数的记录
+func TestRouter_lookup(t *testing.T) {
+	forEachRouter(t, func(t *testing.T, newTestRouter func() router) {
+		r := newTestRouter()
+		mockHandleFunc := func(ctx *Context) {}
+		r.addRoute(http.MethodGet, "/user/:id/order/:orderId", mockHandleFunc)
+		r.addRoute(http.MethodGet, "/user/:id/abc/:id", mockHandleFunc)
+		r.addRoute(http.MethodGet, "/static/*", mockHandleFunc)
+		r.addRoute(http.MethodGet, "/", mockHandleFunc)
+
+		testCases := []struct {
+			name       string
+			path       string
+			isFound    bool
+			wantRoute  string
+			wantParams Params
+		}{
+			{
+				name:      "root",
+				path:      "/",
+				isFound:   true,
+				wantRoute: "/",
+			},
+			{
+				name:      "many params",
+				path:      "/user/1/order/2",
+				isFound:   true,
+				wantRoute: "user/:id/order/:orderId",
+				wantParams: Params{
+					{Key: "id", Value: "1"},
+					{Key: "orderId", Value: "2"},
 				},
-				pathParams: map[string]string{
-					"id": "123",
+			},
+			{
+				name:      "leading and trailing slash",
+				path:      "//user/1/order/2//",
+				isFound:   true,
+				wantRoute: "user/:id/order/:orderId",
+				wantParams: Params{
+					{Key: "id", Value: "1"},
+					{Key: "orderId", Value: "2"},
 				},
 			},
-		},
+			{
+				name:       "wildcard",
+				path:       "/static/app.js",
+				isFound:    true,
+				wantRoute:  "static/*",
+				wantParams: Params{{Key: "*", Value: "app.js"}},
+			},
+			{
+				name:    "not found",
+				path:    "/user/1/detail",
+				isFound: false,
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				params := Params{}
+				target, found := r.lookup(http.MethodGet, testCase.path, &params)
+				assert.Equal(t, testCase.isFound, found)
+				if !found {
+					return
+				}
+
+				assert.Equal(t, testCase.wantRoute, target.route)
+				if len(testCase.wantParams) == 0 {
+					assert.Empty(t, params)
+					return
+				}
+				assert.Equal(t, testCase.wantParams, params)
+			})
+		}
+
+		// 同名路径参数 后者覆盖前者
+		params := Params{}
+		_, found := r.lookup(http.MethodGet, "/user/123/abc/456", &params)
+		assert.True(t, found)
+		id, ok := params.Get("id")
+		assert.True(t, ok)
+		assert.Equal(t, "456", id)
+	})
+}
+
+// legacyFindRoute 改为逐段遍历路径之前的 findRoute 实现 用于基准测试对比
+// 每次查找都会切割路径 并创建 matchNode 和路径参数的map
+func (r *router) legacyFindRoute(method string, path string) (*matchNode, bool) {
+	targetMatchNode := &matchNode{}
+	root, ok := r.trees[method]
+	if !ok {
+		return nil, false
 	}
 
-	for _, testCase := range testCases {
-		t.Run(testCase.name, func(t *testing.T) {
-			foundNode, found := r.findRoute(testCase.method, testCase.path)
-			assert.Equal(t, testCase.isFound, found)
+	if path == "/" {
+		targetMatchNode.node = root
+		return targetMatchNode, true
+	}
 
-			if !found {
-				return
-			}
+	path = strings.Trim(path, "/")
+	segments := strings.Split(path, "/")
 
-			msg, found := testCase.matchNode.node.equal(foundNode.node)
-			assert.True(t, found, msg)
-		})
+	target := root
+	for _, segment := range segments {
+		child, isParamChild, found := target.childOf(segment)
+		if !found {
+			return nil, false
+		}
+
+		if isParamChild {
+			targetMatchNode.addPathParams(child.path[1:], segment)
+		}
+		target = child
 	}
+
+	targetMatchNode.node = target
+	return targetMatchNode, true
 }
 
-// TestRouter_findRoute_param_and_wildcard_coexist 测试针对注册参数路由时,已有通配符路由的情况
-func TestRouter_findRoute_param_and_wildcard_coexist(t *testing.T) {
-	// step1. 注册有冲突的路由
-	r := newRouter()
-	mockHandleFunc := func(ctx *Context) {}
-	r.addRoute(http.MethodGet, "/order/detail/*", mockHandleFunc)
+// childOf 根据给定的path在当前节点的子节点映射中查找对应的子节点(即:匹配到了静态路由)
+// 若未在子节点映射中找到对应子节点 则先尝试返回能匹配path的正则子节点(即:匹配到了正则路由)
+// 若正则子节点为空或不匹配 则尝试返回满足约束的带约束参数子节点(即:匹配到了带约束的参数路由)
+// 若仍未找到 则尝试返回当前节点的参数路由子节点(即:匹配到了参数路由)
+// 若参数路由子节点为空 则尝试返回当前节点的通配符子节点(即:匹配到了通配符路由)
+// 优先级: 静态路由 > 正则路由 > 带约束的参数路由 > 参数路由 > 通配符路由
+// child: 查找到的子节点
+// isParamChild: 查找到的子节点是否为参数路由子节点(正则路由子节点和带约束的参数子节点同样会产生路径参数 因此也视为参数路由子节点)
+// found: 是否找到了对应的子节点
+// Tips: 本方法是贪心匹配 不会回溯 例如注册了 /user/detail/:id/edit 和 /user/*/create 时 /user/detail/create 命中的是没有业务处理函数的 :id 节点 而非 /user/*/create
+// Tips: 查找路由已改为 node.match 本方法仅作为 legacyFindRoute 的一部分 用于基准测试对比
+func (n *node) childOf(path string) (child *node, isParamChild bool, found bool) {
+	// 在子当前节点的静态子节点中查找对应的子节点
+	child, found = n.staticChild(path)
+	if found {
+		return child, false, found
+	}
 
-	// step2. 断言非法用例
-	assert.Panicsf(t, func() {
-		r.addRoute(http.MethodGet, "/order/detail/:id", mockHandleFunc)
-	}, "web: 非法路由,已有通配符路由.不允许同时注册通配符路由和参数路由")
-}
+	// 若未找到 则尝试返回能匹配该路由段的正则子节点
+	// 此处正则路由的优先级高于参数路由 因为正则路由对路由段有约束 更具体
+	if n.regChild != nil && n.regChild.regExp.MatchString(path) {
+		return n.regChild, true, true
+	}
 
-// TestRouter_findRoute_wildcard_and_param_coexist 测试针对注册通配符路由时,已有参数路由的情况
-func TestRouter_findRoute_wildcard_and_param_coexist(t *testing.T) {
-	// step1. 注册有冲突的路由
-	r := newRouter()
-	mockHandleFunc := func(ctx *Context) {}
-	r.addRoute(http.MethodGet, "/order/detail/:id", mockHandleFunc)
+	// 约束不满足时 不视为命中 继续尝试优先级更低的子节点
+	if n.constraintChild != nil && n.constraintChild.validator(path) {
+		return n.constraintChild, true, true
+	}
 
-	// step2. 断言非法用例
-	assert.Panicsf(t, func() {
-		r.addRoute(http.MethodGet, "/order/detail/*", mockHandleFunc)
-	}, "web: 非法路由,已有参数路由.不允许同时注册通配符路由和参数路由")
+	// 再尝试返回当前节点的参数子节点
+	// 此处优先查找参数路由子节点 因为参数路由子节点更具体 所以参数路由的优先级高于通配符路由
+	if n.paramChild != nil {
+		return n.paramChild, true, true
+	}
+
+	// 若参数子节点为空 则尝试返回当前节点的通配符子节点
+	return n.wildcardChild, false, n.wildcardChild != nil
 }
 
-// TestRouter_findRoute_same_param_coexist 测试针对参数路由时,已有同名参数路由的情况
-func TestRouter_findRoute_same_param_coexist(t *testing.T) {
-	// step1. 注册有冲突的路由
+// BenchmarkRouter_findRoute 对比深层静态路由 多参数路由 通配符路由下 lookup 与旧实现的性能
+// 运行方式: go test -bench=BenchmarkRouter_findRoute -benchmem -run=^$
+func BenchmarkRouter_findRoute(b *testing.B) {
 	r := newRouter()
 	mockHandleFunc := func(ctx *Context) {}
-	r.addRoute(http.MethodGet, "/order/detail/:id", mockHandleFunc)
+	r.addRoute(http.MethodGet, "/api/v1/users/profile/settings/notifications/email", mockHandleFunc)
+	r.addRoute(http.MethodGet, "/shop/:shopId/category/:categoryId/item/:itemId/sku/:skuId", mockHandleFunc)
+	r.addRoute(http.MethodGet, "/assets/js/*", mockHandleFunc)
 
-	// step2. 断言非法用例
-	assert.Panicsf(t, func() {
-		r.addRoute(http.MethodGet, "/order/detail/:name", mockHandleFunc)
-	}, "web: 路由冲突,参数路由冲突.已存在路由 id")
+	benchCases := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "deep static",
+			path: "/api/v1/users/profile/settings/notifications/email",
+		},
+		{
+			name: "many params",
+			path: "/shop/1/category/2/item/3/sku/4",
+		},
+		{
+			name: "wildcard tail",
+			path: "/assets/js/app.min.js",
+		},
+	}
+
+	for _, benchCase := range benchCases {
+		b.Run(benchCase.name+"/lookup", func(b *testing.B) {
+			params := make(Params, 0, r.maxParams)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				params = params[:0]
+				_, _ = r.lookup(http.MethodGet, benchCase.path, &params)
+			}
+		})
+
+		b.Run(benchCase.name+"/legacy", func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = r.legacyFindRoute(http.MethodGet, benchCase.path)
+			}
+		})
+	}
 }
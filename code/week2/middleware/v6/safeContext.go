@@ -24,34 +24,22 @@ func (s *SafeContext) SetCookie(cookie *http.Cookie) {
 }
 
 // BindJSON 绑定请求体中的JSON到给定的实例(这里的实例不一定是结构体实例,还有可能是个map)上
+// 绑定成功后根据 validate 标签进行校验 请求体超过最大字节数时响应413 详见 Context.BindJSON
 // 该方法是线程安全的
 func (s *SafeContext) BindJSON(target any) error {
 	s.Lock.Lock()
 	defer s.Lock.Unlock()
 
-	if target == nil {
-		return errors.New("web绑定错误: 给定的实例为空")
-	}
-
-	if s.Context.Req.Body == nil {
-		return errors.New("web绑定错误: 请求体为空")
-	}
-
-	decoder := json.NewDecoder(s.Context.Req.Body)
-	return decoder.Decode(target)
+	return s.Context.BindJSON(target)
 }
 
-// FormValue 获取表单中给定键的值 该方法是线程安全的
+// FormValue 获取表单中给定键的值 请求体超过最大字节数时响应413 详见 Context.FormValue
+// 该方法是线程安全的
 func (s *SafeContext) FormValue(key string) (stringValue StringValue) {
 	s.Lock.Lock()
 	defer s.Lock.Unlock()
 
-	err := s.Context.Req.ParseForm()
-	if err != nil {
-		return StringValue{err: err}
-	}
-
-	return StringValue{value: s.Context.Req.FormValue(key)}
+	return s.Context.FormValue(key)
 }
 
 // QueryValue 获取查询字符串中给定键的值 该方法是线程安全的
@@ -80,11 +68,11 @@ func (s *SafeContext) PathValue(key string) (stringValue StringValue) {
 	s.Lock.Lock()
 	defer s.Lock.Unlock()
 
-	if s.Context.PathParams == nil {
+	if len(s.Context.PathParams) == 0 {
 		return StringValue{err: errors.New("web绑定错误: 无任何路径参数")}
 	}
 
-	value, ok := s.Context.PathParams[key]
+	value, ok := s.Context.PathParams.Get(key)
 	if !ok {
 		return StringValue{err: errors.New("web绑定错误: 路径参数中不存在键: " + key)}
 	}
@@ -123,3 +111,19 @@ func (s *SafeContext) RespJSONOK(obj any) (err error) {
 
 	return s.Context.RespJSON(http.StatusOK, obj)
 }
+
+// Negotiate 根据请求头 Accept 选择编解码器输出响应 详见 Context.Negotiate 该方法是线程安全的
+func (s *SafeContext) Negotiate(status int, obj any) error {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	return s.Context.Negotiate(status, obj)
+}
+
+// Render 使用模板引擎渲染给定名称的模板 详见 Context.Render 该方法是线程安全的
+func (s *SafeContext) Render(status int, name string, data any) error {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	return s.Context.Render(status, name, data)
+}
@@ -1,10 +1,16 @@
 package web
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 // Server WEB服务器接口
 type Server interface {
-	http.Handler                                                // Handler 组合http.Handler接口
-	Start(addr string) error                                    // Start 启动WEB服务器
-	addRoute(method string, path string, handleFunc HandleFunc) // addRoute 注册路由
+	http.Handler                                                                    // Handler 组合http.Handler接口
+	Start(addr string) error                                                        // Start 启动WEB服务器
+	Shutdown(ctx context.Context) error                                             // Shutdown 优雅退出WEB服务器
+	OnStart(hooks ...Hook)                                                          // OnStart 注册启动钩子
+	OnShutdown(hooks ...Hook)                                                       // OnShutdown 注册退出钩子
+	addRoute(method string, path string, handleFunc HandleFunc, mdls ...Middleware) // addRoute 注册路由
 }
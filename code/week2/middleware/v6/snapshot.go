@@ -0,0 +1,71 @@
+package web
+
+// 运行时注册与删除路由
+// HTTPServer 上内嵌的路由森林是可写的主路由森林 所有的写操作(注册路由 删除路由 注册中间件 命名路由 创建主机路由)
+// 都在 routerMutex 的保护下修改主路由森林 并使当前的快照失效
+// 查找路由时使用主路由森林的只读快照 快照在写操作之后第一次查找路由时由主路由森林深拷贝得到 并通过原子操作替换
+// 因此查找路由的过程无需加锁 且写操作不会影响正在使用旧快照处理的请求
+// Tips: 启动前连续注册大量路由时只会使快照失效 不会重复拷贝 拷贝只发生在写操作之后的第一个请求上
+
+// currentRouter 返回用于查找路由的只读路由森林快照 若快照已失效则重新生成
+func (s *HTTPServer) currentRouter() *router {
+	if rt := s.snapshot.Load(); rt != nil {
+		return rt
+	}
+
+	s.routerMutex.Lock()
+	defer s.routerMutex.Unlock()
+
+	// Tips: double check 其他goroutine可能已经在等待锁的期间生成了快照
+	if rt := s.snapshot.Load(); rt != nil {
+		return rt
+	}
+
+	rt := s.router.clone()
+	s.snapshot.Store(rt)
+	return rt
+}
+
+// updateRouter 在写锁的保护下修改主路由森林 并使当前的快照失效
+// 即使fn因路由冲突等原因panic 快照依旧会失效 锁依旧会被释放
+func (s *HTTPServer) updateRouter(fn func(r *router)) {
+	s.routerMutex.Lock()
+	defer func() {
+		s.snapshot.Store(nil)
+		s.routerMutex.Unlock()
+	}()
+
+	fn(&s.router)
+}
+
+// addRoute 注册路由 注册失败时panic 可以在服务运行期间调用
+func (s *HTTPServer) addRoute(method string, path string, handleFunc HandleFunc, mdls ...Middleware) {
+	if err := s.register(nil, method, path, handleFunc, mdls...); err != nil {
+		panic(err)
+	}
+}
+
+// register 注册路由 注册失败时返回 *RouteError 可以在服务运行期间调用
+// host不为nil时 注册到给定主机模式的路由森林上
+func (s *HTTPServer) register(host *hostRouter, method string, path string, handleFunc HandleFunc, mdls ...Middleware) error {
+	var err error
+	s.updateRouter(func(r *router) {
+		if host != nil {
+			r = host.router
+		}
+		err = r.register(method, path, handleFunc, mdls...)
+	})
+	return err
+}
+
+// RemoveRoute 删除给定的路由 返回路由是否存在 可以在服务运行期间调用
+// 仅删除直接在 HTTPServer 上注册的路由 绑定到主机模式上的路由不受影响
+// 删除后没有业务处理函数也没有子节点的节点会被一并删除 指向该路由的路由名称同样会被删除
+// 已经命中该路由的请求会继续执行完毕
+func (s *HTTPServer) RemoveRoute(method string, path string) bool {
+	removed := false
+	s.updateRouter(func(r *router) {
+		removed = r.removeRoute(method, path)
+	})
+	return removed
+}
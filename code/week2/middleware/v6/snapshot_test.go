@@ -0,0 +1,152 @@
+package web
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestHTTPServer_RemoveRoute 测试删除路由以及空节点的删除
+func TestHTTPServer_RemoveRoute(t *testing.T) {
+	handleFunc := func(ctx *Context) {
+		ctx.RespData = []byte(ctx.MatchRoute)
+	}
+
+	testCases := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "map router"},
+		{name: "radix router", opts: []Option{ServerWithRadixRouter()}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			s := NewHTTPServer(testCase.opts...)
+			s.GET("/user", handleFunc)
+			s.GET("/user/home", handleFunc).Name("home")
+			s.GET("/users/:id", handleFunc)
+			s.GET("/order/detail", handleFunc)
+			s.POST("/order", handleFunc)
+
+			// 先请求一次 生成快照 验证删除路由后快照会失效
+			assert.Equal(t, http.StatusOK, serveStatus(s, http.MethodGet, "/user/home"))
+
+			// 不存在的路由 以及没有业务处理函数的节点
+			assert.False(t, s.RemoveRoute(http.MethodGet, "/user/detail"))
+			assert.False(t, s.RemoveRoute(http.MethodGet, "/order"))
+			assert.False(t, s.RemoveRoute(http.MethodPut, "/user"))
+
+			// 删除叶子节点 同时删除指向该路由的路由名称
+			assert.True(t, s.RemoveRoute(http.MethodGet, "/user/home"))
+			assert.False(t, s.RemoveRoute(http.MethodGet, "/user/home"))
+			assert.Equal(t, http.StatusNotFound, serveStatus(s, http.MethodGet, "/user/home"))
+			assert.Equal(t, http.StatusOK, serveStatus(s, http.MethodGet, "/user"))
+			_, err := s.URLFor("home", nil, nil)
+			assert.Error(t, err)
+			user, _ := s.trees[http.MethodGet].segmentChild("user")
+			assert.False(t, user.hasChildren())
+
+			// 删除有子节点的节点 仅清除业务处理函数
+			assert.True(t, s.RemoveRoute(http.MethodGet, "/user"))
+			assert.Equal(t, http.StatusOK, serveStatus(s, http.MethodGet, "/users/12"))
+			_, ok := s.trees[http.MethodGet].segmentChild("user")
+			assert.False(t, ok)
+
+			// 自底向上删除没有业务处理函数也没有子节点的节点
+			assert.True(t, s.RemoveRoute(http.MethodGet, "/order/detail"))
+			_, ok = s.trees[http.MethodGet].segmentChild("order")
+			assert.False(t, ok)
+			_, ok = s.trees[http.MethodGet].segmentChild("users")
+			assert.True(t, ok)
+
+			// 路由树为空时删除整棵路由树
+			assert.True(t, s.RemoveRoute(http.MethodGet, "/users/:id"))
+			_, ok = s.trees[http.MethodGet]
+			assert.False(t, ok)
+			assert.Equal(t, http.StatusNotFound, serveStatus(s, http.MethodGet, "/users/12"))
+			assert.Equal(t, http.StatusOK, serveStatus(s, http.MethodPost, "/order"))
+
+			// 删除后可以重新注册
+			s.GET("/user/home", handleFunc)
+			assert.Equal(t, http.StatusOK, serveStatus(s, http.MethodGet, "/user/home"))
+		})
+	}
+}
+
+// TestRadixNode_remove 测试压缩前缀树删除路由段后的合并
+func TestRadixNode_remove(t *testing.T) {
+	root := &radixNode{}
+	for _, key := range []string{"user", "users", "user_groups"} {
+		root.insert(key, &node{path: key})
+	}
+
+	assert.False(t, root.remove("use"))
+	assert.False(t, root.remove("order"))
+
+	// 删除后只剩一条边且自身没有值的节点与其子节点合并
+	assert.True(t, root.remove("user"))
+	user := root.edgeOf('u')
+	assert.Equal(t, "user", user.prefix)
+	assert.Nil(t, user.value)
+	assert.Len(t, user.edges, 2)
+
+	assert.True(t, root.remove("users"))
+	user = root.edgeOf('u')
+	assert.Equal(t, "user_groups", user.prefix)
+	assert.Empty(t, user.edges)
+
+	assert.True(t, root.remove("user_groups"))
+	assert.True(t, root.empty())
+}
+
+// TestHTTPServer_concurrentRoutes 测试处理请求的同时注册与删除路由 需使用 -race 运行
+func TestHTTPServer_concurrentRoutes(t *testing.T) {
+	s := NewHTTPServer()
+	s.GET("/stable", func(ctx *Context) {
+		ctx.RespData = []byte("stable")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				path := fmt.Sprintf("/dynamic/%d/%d", i, j)
+				s.GET(path, func(ctx *Context) {})
+				s.Group("/group").GET(path, func(ctx *Context) {})
+				s.Use(path, func(next HandleFunc) HandleFunc {
+					return next
+				})
+				assert.True(t, s.RemoveRoute(http.MethodGet, path))
+			}
+		}(i)
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				assert.Equal(t, http.StatusOK, serveStatus(s, http.MethodGet, "/stable"))
+				_ = s.Routes()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, http.StatusNotFound, serveStatus(s, http.MethodGet, "/dynamic/0/0"))
+	assert.Equal(t, http.StatusOK, serveStatus(s, http.MethodGet, "/group/dynamic/0/0"))
+}
+
+// serveStatus 使用给定的服务器处理请求 返回响应的状态码
+func serveStatus(s *HTTPServer, method string, target string) int {
+	request := httptest.NewRequest(method, target, nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	return recorder.Code
+}
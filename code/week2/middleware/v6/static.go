@@ -0,0 +1,326 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaticOption 静态文件服务的选项
+type StaticOption func(h *staticHandler)
+
+// staticHandler 静态文件服务
+type staticHandler struct {
+	fsys          fs.FS    // fsys 静态文件所在的文件系统 例如 embed.FS 或 os.DirFS
+	index         string   // index 请求目录时返回的目录下的索引文件 为空时不返回索引文件
+	fallback      string   // fallback 文件不存在时返回的文件 用于单页应用(SPA)的前端路由 为空时响应404
+	listDirectory bool     // listDirectory 请求的目录下没有索引文件时 是否列出目录下的文件
+	precompressed bool     // precompressed 是否优先返回预压缩的 .gz 文件
+	etags         sync.Map // etags 文件内容的强ETag缓存 key为 etagKey value为ETag 避免每次请求都计算摘要
+}
+
+// etagKey ETag缓存的key 文件的修改时间或大小变化时 缓存自然失效
+type etagKey struct {
+	name    string    // name 文件名
+	modTime time.Time // modTime 文件的修改时间 embed.FS 中的文件修改时间为零值
+	size    int64     // size 文件大小
+}
+
+// StaticWithIndex 设置请求目录时返回的索引文件 默认为 index.html 设置为空字符串时不返回索引文件
+func StaticWithIndex(name string) StaticOption {
+	return func(h *staticHandler) {
+		h.index = name
+	}
+}
+
+// StaticWithFallback 设置文件不存在时返回的文件 用于单页应用(SPA)的前端路由
+// 例如 StaticWithFallback("index.html") 使得 /app/user/12 这种前端路由返回 index.html 而非404
+func StaticWithFallback(name string) StaticOption {
+	return func(h *staticHandler) {
+		h.fallback = name
+	}
+}
+
+// StaticWithDirectoryListing 开启目录列表 请求的目录下没有索引文件时 列出目录下的文件 默认关闭
+func StaticWithDirectoryListing() StaticOption {
+	return func(h *staticHandler) {
+		h.listDirectory = true
+	}
+}
+
+// StaticWithPrecompressed 开启预压缩文件支持 默认关闭
+// 开启后 若请求头 Accept-Encoding 接受gzip 且文件旁存在同名的 .gz 文件(例如 app.js 旁的 app.js.gz) 则返回 .gz 文件的内容
+// 并设置响应头 Content-Encoding: gzip Content-Type 依旧以原文件的扩展名为准
+func StaticWithPrecompressed() StaticOption {
+	return func(h *staticHandler) {
+		h.precompressed = true
+	}
+}
+
+// Static 将文件系统中的静态文件挂载到给定的路由前缀上 例如:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//	sub, _ := fs.Sub(dist, "dist")
+//	s.Static("/assets", sub, StaticWithFallback("index.html"))
+//	s.Static("/public", os.DirFS("./public"))
+//
+// 请求 /assets/js/app.js 时返回文件系统中的 js/app.js 支持:
+// 1. 强ETag与 If-None-Match If-Modified-Since 条件请求 命中时响应304
+// 2. Range 范围请求 响应206
+// 3. 预压缩的 .gz 文件 详见 StaticWithPrecompressed
+// 4. 请求目录时返回索引文件 详见 StaticWithIndex 以及单页应用的回退文件 详见 StaticWithFallback
+// 请求路径中包含".."路由段时响应404 因此无法访问文件系统之外的文件 目录列表默认关闭
+// Tips: 本方法基于通配符路由实现 注册了 前缀 与 前缀/* 两个GET请求路由 HEAD请求使用GET请求路由处理
+func (s *HTTPServer) Static(prefix string, fsys fs.FS, opts ...StaticOption) {
+	s.Group("/").Static(prefix, fsys, opts...)
+}
+
+// Static 在分组下挂载静态文件 详见 HTTPServer.Static
+func (g *Group) Static(prefix string, fsys fs.FS, opts ...StaticOption) {
+	h := &staticHandler{
+		fsys:  fsys,
+		index: "index.html",
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	wildcard := prefix + "/*"
+	if prefix == "/" {
+		wildcard = "/*"
+	}
+	g.addRoute(http.MethodGet, prefix, h.serve)
+	g.addRoute(http.MethodGet, wildcard, h.serve)
+}
+
+// serve 处理静态文件请求
+func (h *staticHandler) serve(ctx *Context) {
+	rest, _ := ctx.PathParams.Get("*")
+	// 拒绝包含".."路由段的请求 而非清理后再查找 避免越过前缀访问文件
+	for _, segment := range strings.Split(rest, "/") {
+		if segment == ".." {
+			notFound(ctx)
+			return
+		}
+	}
+
+	name := strings.Trim(rest, "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil {
+		h.serveFallback(ctx, err)
+		return
+	}
+
+	if !info.IsDir() {
+		h.serveFile(ctx, name, info)
+		return
+	}
+
+	if h.index != "" {
+		indexName := path.Join(name, h.index)
+		if indexInfo, err := fs.Stat(h.fsys, indexName); err == nil && !indexInfo.IsDir() {
+			h.serveFile(ctx, indexName, indexInfo)
+			return
+		}
+	}
+
+	if h.listDirectory {
+		h.serveDirectory(ctx, name)
+		return
+	}
+	h.serveFallback(ctx, fs.ErrNotExist)
+}
+
+// serveFallback 文件不存在时返回回退文件 未设置回退文件或其他错误时响应对应的错误
+func (h *staticHandler) serveFallback(ctx *Context, err error) {
+	if h.fallback != "" && !errors.Is(err, fs.ErrPermission) {
+		if info, statErr := fs.Stat(h.fsys, h.fallback); statErr == nil && !info.IsDir() {
+			h.serveFile(ctx, h.fallback, info)
+			return
+		}
+	}
+	serveFSError(ctx, err)
+}
+
+// serveFile 返回文件内容 条件请求与范围请求由 http.ServeContent 处理
+// 缓存了文件的ETag时 条件请求命中不会读取文件 范围请求只读取请求的范围 详见 staticHandler.etag
+func (h *staticHandler) serveFile(ctx *Context, name string, info fs.FileInfo) {
+	header := ctx.Resp.Header()
+
+	// 优先返回预压缩的文件 此时响应的是文件的另一种表示 因此ETag与范围请求均基于压缩后的内容
+	if h.precompressed {
+		header.Add("Vary", "Accept-Encoding")
+		if acceptsGzip(ctx.Req.Header.Get("Accept-Encoding")) {
+			gzName := name + ".gz"
+			if gzInfo, err := fs.Stat(h.fsys, gzName); err == nil && !gzInfo.IsDir() {
+				contentType := mime.TypeByExtension(path.Ext(name))
+				if contentType == "" {
+					contentType = "application/octet-stream"
+				}
+				header.Set("Content-Type", contentType)
+				header.Set("Content-Encoding", "gzip")
+				name, info = gzName, gzInfo
+			}
+		}
+	}
+
+	file, err := h.fsys.Open(name)
+	if err != nil {
+		header.Del("Content-Encoding")
+		serveFSError(ctx, err)
+		return
+	}
+	defer file.Close()
+
+	// Tips: 不支持 io.Seeker 的文件 只有在需要响应体时才读取整个文件
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		content = &readAllSeeker{r: file}
+	}
+
+	etag, err := h.etag(name, info, content)
+	if err != nil {
+		header.Del("Content-Encoding")
+		serveFSError(ctx, err)
+		return
+	}
+
+	header.Set("ETag", etag)
+	// 条件请求命中时 http.ServeContent 不会读取文件 范围请求时只读取请求的范围
+	// Tips: 响应先记录在上下文中 由 flashResp 统一写入 详见 respRecorder
+	http.ServeContent(&respRecorder{ctx: ctx, header: header}, ctx.Req, path.Base(name), info.ModTime(), content)
+}
+
+// etag 返回文件内容的强ETag 即文件内容的SHA-256摘要
+// 缓存命中时不读取文件 否则读取整个文件计算摘要 并将content定位回文件开头
+func (h *staticHandler) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := etagKey{name: name, modTime: info.ModTime(), size: info.Size()}
+	if etag, ok := h.etags.Load(key); ok {
+		return etag.(string), nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	h.etags.Store(key, etag)
+	return etag, nil
+}
+
+// readAllSeeker 将不支持 io.Seeker 的文件包装为 io.ReadSeeker 首次读取或定位时才读取整个文件
+type readAllSeeker struct {
+	r    io.Reader     // r 被包装的文件
+	data *bytes.Reader // data 文件的全部内容 为nil时表示尚未读取
+}
+
+// load 读取整个文件 仅在首次调用时读取
+func (s *readAllSeeker) load() error {
+	if s.data != nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(s.r)
+	if err != nil {
+		return err
+	}
+	s.data = bytes.NewReader(data)
+	return nil
+}
+
+// Read 实现 io.Reader
+func (s *readAllSeeker) Read(p []byte) (int, error) {
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	return s.data.Read(p)
+}
+
+// Seek 实现 io.Seeker
+func (s *readAllSeeker) Seek(offset int64, whence int) (int64, error) {
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	return s.data.Seek(offset, whence)
+}
+
+// serveDirectory 列出目录下的文件
+func (h *staticHandler) serveDirectory(ctx *Context, name string) {
+	entries, err := fs.ReadDir(h.fsys, name)
+	if err != nil {
+		serveFSError(ctx, err)
+		return
+	}
+
+	// 查找路由时忽略了请求路径末尾的"/" 因此此处使用绝对路径生成链接 避免相对路径解析错误
+	base := strings.TrimSuffix(ctx.Req.URL.Path, "/") + "/"
+	var sb strings.Builder
+	sb.WriteString("<!doctype html>\n<pre>\n")
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+		link := (&url.URL{Path: base + entryName}).EscapedPath()
+		fmt.Fprintf(&sb, "<a href=\"%s\">%s</a>\n", html.EscapeString(link), html.EscapeString(entryName))
+	}
+	sb.WriteString("</pre>\n")
+
+	ctx.Resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx.RespStatusCode = http.StatusOK
+	ctx.RespData = []byte(sb.String())
+}
+
+// serveFSError 根据文件系统返回的错误设置响应
+// 文件不存在或路径非法时响应404 没有权限时响应403 其他错误响应500
+func serveFSError(ctx *Context, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrInvalid):
+		notFound(ctx)
+	case errors.Is(err, fs.ErrPermission):
+		ctx.RespStatusCode = http.StatusForbidden
+		ctx.RespData = []byte("Forbidden")
+	default:
+		ctx.RespStatusCode = http.StatusInternalServerError
+		ctx.RespData = []byte("Internal Server Error")
+	}
+}
+
+// acceptsGzip 判断请求头 Accept-Encoding 是否接受gzip编码 q=0表示不接受
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.TrimSpace(coding)
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		if q == "q=0" || strings.HasPrefix(q, "q=0.") && strings.Trim(q[len("q=0."):], "0") == "" {
+			return false
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,329 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// TestHTTPServer_Static 测试静态文件服务
+func TestHTTPServer_Static(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	files := fstest.MapFS{
+		"index.html":     {Data: []byte("<html>home</html>"), ModTime: modTime},
+		"js/app.js":      {Data: []byte("console.log(1)"), ModTime: modTime},
+		"js/app.js.gz":   {Data: []byte("gzipped"), ModTime: modTime},
+		"css/app.css":    {Data: []byte("body{}"), ModTime: modTime},
+		"docs/readme.md": {Data: []byte("# readme"), ModTime: modTime},
+	}
+
+	etagOf := func(s *HTTPServer, target string, header http.Header) string {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		for key := range header {
+			request.Header.Set(key, header.Get(key))
+		}
+		recorder := httptest.NewRecorder()
+		s.ServeHTTP(recorder, request)
+		return recorder.Header().Get("ETag")
+	}
+
+	s := NewHTTPServer()
+	s.Static("/static", files, StaticWithPrecompressed())
+	s.Group("/app").Static("/", files, StaticWithFallback("index.html"))
+	s.Static("/files", files, StaticWithIndex(""), StaticWithDirectoryListing())
+	etag := etagOf(s, "/static/css/app.css", nil)
+	require.NotEmpty(t, etag)
+
+	testCases := []struct {
+		name       string
+		method     string
+		target     string
+		header     map[string]string
+		wantCode   int
+		wantBody   string
+		wantHeader map[string]string
+	}{
+		{
+			name:       "file",
+			target:     "/static/css/app.css",
+			wantCode:   http.StatusOK,
+			wantBody:   "body{}",
+			wantHeader: map[string]string{"Content-Type": "text/css; charset=utf-8", "ETag": etag, "Last-Modified": modTime.Format(http.TimeFormat)},
+		},
+		{
+			name:       "head",
+			method:     http.MethodHead,
+			target:     "/static/css/app.css",
+			wantCode:   http.StatusOK,
+			wantHeader: map[string]string{"Content-Length": "6"},
+		},
+		{
+			name:     "if none match",
+			target:   "/static/css/app.css",
+			header:   map[string]string{"If-None-Match": etag},
+			wantCode: http.StatusNotModified,
+		},
+		{
+			name:     "if none match changed",
+			target:   "/static/css/app.css",
+			header:   map[string]string{"If-None-Match": `"other"`},
+			wantCode: http.StatusOK,
+			wantBody: "body{}",
+		},
+		{
+			name:     "if modified since",
+			target:   "/static/css/app.css",
+			header:   map[string]string{"If-Modified-Since": modTime.Add(time.Hour).Format(http.TimeFormat)},
+			wantCode: http.StatusNotModified,
+		},
+		{
+			name:       "range",
+			target:     "/static/css/app.css",
+			header:     map[string]string{"Range": "bytes=0-3"},
+			wantCode:   http.StatusPartialContent,
+			wantBody:   "body",
+			wantHeader: map[string]string{"Content-Range": "bytes 0-3/6"},
+		},
+		{
+			name:       "precompressed",
+			target:     "/static/js/app.js",
+			header:     map[string]string{"Accept-Encoding": "br, gzip"},
+			wantCode:   http.StatusOK,
+			wantBody:   "gzipped",
+			wantHeader: map[string]string{"Content-Encoding": "gzip", "Content-Type": "text/javascript; charset=utf-8", "Vary": "Accept-Encoding"},
+		},
+		{
+			name:       "gzip not accepted",
+			target:     "/static/js/app.js",
+			header:     map[string]string{"Accept-Encoding": "gzip;q=0"},
+			wantCode:   http.StatusOK,
+			wantBody:   "console.log(1)",
+			wantHeader: map[string]string{"Content-Encoding": "", "Vary": "Accept-Encoding"},
+		},
+		{
+			name:     "index",
+			target:   "/static",
+			wantCode: http.StatusOK,
+			wantBody: "<html>home</html>",
+		},
+		{
+			name:     "directory without index",
+			target:   "/static/js/",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:     "not found",
+			target:   "/static/js/missing.js",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:     "path traversal",
+			target:   "/static/js/../../secret",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:     "spa fallback",
+			target:   "/app/user/12",
+			wantCode: http.StatusOK,
+			wantBody: "<html>home</html>",
+		},
+		{
+			name:     "spa file",
+			target:   "/app/css/app.css",
+			wantCode: http.StatusOK,
+			wantBody: "body{}",
+		},
+		{
+			name:     "spa path traversal",
+			target:   "/app/../secret",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found",
+		},
+		{
+			name:       "directory listing",
+			target:     "/files/docs",
+			wantCode:   http.StatusOK,
+			wantBody:   "<!doctype html>\n<pre>\n<a href=\"/files/docs/readme.md\">readme.md</a>\n</pre>\n",
+			wantHeader: map[string]string{"Content-Type": "text/html; charset=utf-8"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			method := testCase.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			request := httptest.NewRequest(method, testCase.target, nil)
+			for key, value := range testCase.header {
+				request.Header.Set(key, value)
+			}
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, request)
+			assert.Equal(t, testCase.wantCode, recorder.Code)
+			assert.Equal(t, testCase.wantBody, recorder.Body.String())
+			for key, value := range testCase.wantHeader {
+				assert.Equal(t, value, recorder.Header().Get(key), key)
+			}
+		})
+	}
+
+	// 预压缩文件的ETag与原文件不同
+	assert.NotEqual(t, etagOf(s, "/static/js/app.js", nil), etagOf(s, "/static/js/app.js", http.Header{"Accept-Encoding": {"gzip"}}))
+}
+
+// TestHTTPServer_Static_dirFS 测试使用 os.DirFS 提供静态文件
+func TestHTTPServer_Static_dirFS(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello"), 0o644))
+
+	s := NewHTTPServer()
+	s.Static("/", os.DirFS(dir))
+
+	request := httptest.NewRequest(http.MethodGet, "/hello.txt", nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "hello", recorder.Body.String())
+	assert.Equal(t, "text/plain; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	// 文件内容变化后ETag随之变化
+	etag := recorder.Header().Get("ETag")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello world"), 0o644))
+	recorder = httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/hello.txt", nil))
+	assert.Equal(t, "hello world", recorder.Body.String())
+	assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+
+	// 请求不存在的文件
+	recorder = httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing.txt", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+// countingFS 记录读取文件内容的字节数 seekable为false时打开的文件不支持 io.Seeker
+type countingFS struct {
+	fstest.MapFS
+	read     int  // read 读取文件内容的字节数
+	seekable bool // seekable 打开的文件是否支持 io.Seeker
+}
+
+// Open 打开文件 返回的文件在读取时记录读取的字节数
+func (c *countingFS) Open(name string) (fs.File, error) {
+	f, err := c.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &countingFile{File: f, fsys: c}
+	if c.seekable {
+		return &seekableCountingFile{countingFile: file}, nil
+	}
+	return file, nil
+}
+
+// countingFile 读取时记录读取字节数的文件
+type countingFile struct {
+	fs.File
+	fsys *countingFS
+}
+
+// Read 读取文件内容并记录读取的字节数
+func (f *countingFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.fsys.read += n
+	return n, err
+}
+
+// seekableCountingFile 支持 io.Seeker 的 countingFile
+type seekableCountingFile struct {
+	*countingFile
+}
+
+// Seek 实现 io.Seeker
+func (f *seekableCountingFile) Seek(offset int64, whence int) (int64, error) {
+	return f.File.(io.Seeker).Seek(offset, whence)
+}
+
+// TestHTTPServer_Static_read 测试条件请求命中缓存的ETag时不读取文件 范围请求只读取请求的范围
+func TestHTTPServer_Static_read(t *testing.T) {
+	data := "0123456789"
+	testCases := []struct {
+		name     string
+		seekable bool
+		// wantRangeRead 范围请求读取的字节数 不支持 io.Seeker 的文件只能读取整个文件
+		wantRangeRead int
+	}{
+		{name: "seekable", seekable: true, wantRangeRead: 4},
+		{name: "not seekable", seekable: false, wantRangeRead: len(data)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fsys := &countingFS{MapFS: fstest.MapFS{"a.txt": {Data: []byte(data)}}, seekable: testCase.seekable}
+			s := NewHTTPServer()
+			s.Static("/", fsys)
+
+			serve := func(header map[string]string) *httptest.ResponseRecorder {
+				fsys.read = 0
+				request := httptest.NewRequest(http.MethodGet, "/a.txt", nil)
+				for key, value := range header {
+					request.Header.Set(key, value)
+				}
+				recorder := httptest.NewRecorder()
+				s.ServeHTTP(recorder, request)
+				return recorder
+			}
+
+			// 首次请求时计算ETag 读取整个文件
+			recorder := serve(nil)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, data, recorder.Body.String())
+			etag := recorder.Header().Get("ETag")
+			require.NotEmpty(t, etag)
+
+			recorder = serve(map[string]string{"If-None-Match": etag})
+			assert.Equal(t, http.StatusNotModified, recorder.Code)
+			assert.Zero(t, fsys.read)
+
+			recorder = serve(map[string]string{"Range": "bytes=2-5"})
+			assert.Equal(t, http.StatusPartialContent, recorder.Code)
+			assert.Equal(t, "2345", recorder.Body.String())
+			assert.Equal(t, testCase.wantRangeRead, fsys.read)
+		})
+	}
+}
+
+// TestAcceptsGzip 测试解析请求头 Accept-Encoding
+func TestAcceptsGzip(t *testing.T) {
+	testCases := []struct {
+		name           string
+		acceptEncoding string
+		want           bool
+	}{
+		{name: "empty", acceptEncoding: "", want: false},
+		{name: "gzip", acceptEncoding: "gzip", want: true},
+		{name: "list", acceptEncoding: "deflate, gzip;q=0.8", want: true},
+		{name: "any", acceptEncoding: "*", want: true},
+		{name: "rejected", acceptEncoding: "gzip;q=0", want: false},
+		{name: "rejected with decimals", acceptEncoding: "gzip; q=0.000", want: false},
+		{name: "other", acceptEncoding: "br", want: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, acceptsGzip(testCase.acceptEncoding))
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrTemplateNotFound 渲染的模板不存在
+var ErrTemplateNotFound = errors.New("web: 模板不存在")
+
+// TemplateEngine 模板引擎 通过 ServerWithTemplateEngine 设置后 即可使用 Context.Render 渲染模板
+type TemplateEngine interface {
+	// Render 渲染给定名称的模板 并将结果写入w中 data为模板数据
+	Render(w io.Writer, name string, data any) error
+}
+
+// serverAware 需要关联HTTP服务器的模板引擎 例如在模板中根据路由名称生成URL
+type serverAware interface {
+	setServer(server *HTTPServer)
+}
+
+// ServerWithTemplateEngine 本函数用于设置 HTTPServer 实例的模板引擎
+// 模板引擎为 HTMLTemplateEngine 时 会与 HTTPServer 实例关联 以便在模板中使用 URLFor 函数
+// Tips: 同一个 HTMLTemplateEngine 实例被多个 HTTPServer 使用时 URLFor 使用最后一个关联的 HTTPServer 生成URL
+func ServerWithTemplateEngine(engine TemplateEngine) Option {
+	return func(server *HTTPServer) {
+		server.templateEngine = engine
+		if aware, ok := engine.(serverAware); ok {
+			aware.setServer(server)
+		}
+	}
+}
+
+// Render 使用 ServerWithTemplateEngine 设置的模板引擎渲染给定名称的模板 并以HTML格式输出响应
+// 模板先渲染到缓冲区中 渲染失败时返回错误 不修改响应 因此不会输出渲染到一半的页面
+func (c *Context) Render(status int, name string, data any) error {
+	if c.server == nil || c.server.templateEngine == nil {
+		return errors.New("web: 未设置模板引擎 无法渲染模板")
+	}
+
+	var buf bytes.Buffer
+	if err := c.server.templateEngine.Render(&buf, name, data); err != nil {
+		return err
+	}
+
+	c.Resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Resp.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	c.RespStatusCode = status
+	c.RespData = buf.Bytes()
+	return nil
+}
+
+// HTMLTemplateOption HTMLTemplateEngine 的选项
+type HTMLTemplateOption func(e *HTMLTemplateEngine)
+
+// HTMLTemplateEngine 基于 html/template 的模板引擎 从 fs.FS 中加载模板 模板名称为模板文件在文件系统中的路径 例如:
+//
+//	templates/
+//	├── layouts/base.html   <html><body>{{block "content" .}}{{end}}</body></html>
+//	├── partials/nav.html   <nav><a href="{{URLFor "user" "id" .ID}}">me</a></nav>
+//	└── users/show.html     {{define "content"}}{{template "partials/nav.html" .}}<p>{{.Name}}</p>{{end}}
+//
+//	engine, err := NewHTMLTemplateEngine(os.DirFS("templates"), HTMLTemplateWithLayout("layouts/base.html"))
+//	s := NewHTTPServer(ServerWithTemplateEngine(engine))
+//	s.GET("/users/:id", func(ctx *Context) {
+//		_ = ctx.Render(http.StatusOK, "users/show.html", user)
+//	})
+//
+// 模板分为两类:
+// 1. 共享模板 即布局与局部模板 默认为 layouts/*.html 与 partials/*.html 详见 HTMLTemplateWithShared 所有页面模板中均可引用
+// 2. 页面模板 即其他扩展名为 .html 的文件 每个页面模板与共享模板单独组成一个模板集合 因此不同页面可以定义同名的模板(例如content)
+// 设置了布局时 渲染页面模板即执行布局模板 布局中通过 block 或 template 引用页面定义的模板 未设置布局时直接执行页面模板
+// 共享模板同样可以直接渲染 例如渲染局部模板用于响应局部刷新的请求
+// 内置函数 URLFor 根据路由名称生成URL 参数为路由名称以及交替出现的路径参数名与值 例如 {{URLFor "user" "id" 12}}
+type HTMLTemplateEngine struct {
+	fsys    fs.FS                       // fsys 模板所在的文件系统 例如 embed.FS 或 os.DirFS
+	shared  []string                    // shared 共享模板的匹配模式 语法与 fs.Glob 相同
+	ext     string                      // ext 页面模板的扩展名
+	layout  string                      // layout 布局模板的名称 为空时直接执行页面模板
+	funcs   template.FuncMap            // funcs 自定义模板函数
+	devMode bool                        // devMode 是否开启开发模式 开启后模板文件变化时重新解析
+	server  atomic.Pointer[HTTPServer]  // server 关联的HTTP服务器 用于 URLFor 函数
+	mutex   sync.Mutex                  // mutex 保护开发模式下的重新解析
+	set     atomic.Pointer[templateSet] // set 已解析的模板集合
+}
+
+// templateSet 一次解析得到的所有模板
+type templateSet struct {
+	shared      *template.Template            // shared 只包含共享模板的模板集合 用于直接渲染共享模板
+	pages       map[string]*template.Template // pages 页面模板名称到其模板集合的映射
+	fingerprint string                        // fingerprint 解析时文件系统中所有文件的指纹 用于在开发模式下判断模板文件是否变化
+}
+
+// HTMLTemplateWithShared 设置共享模板(布局与局部模板)的匹配模式 语法与 fs.Glob 相同 默认为 layouts/*.html 与 partials/*.html
+func HTMLTemplateWithShared(patterns ...string) HTMLTemplateOption {
+	return func(e *HTMLTemplateEngine) {
+		e.shared = patterns
+	}
+}
+
+// HTMLTemplateWithExtension 设置页面模板的扩展名 默认为 .html
+func HTMLTemplateWithExtension(ext string) HTMLTemplateOption {
+	return func(e *HTMLTemplateEngine) {
+		e.ext = ext
+	}
+}
+
+// HTMLTemplateWithLayout 设置渲染页面模板时执行的布局模板 name为共享模板中定义的模板名称 默认不使用布局
+func HTMLTemplateWithLayout(name string) HTMLTemplateOption {
+	return func(e *HTMLTemplateEngine) {
+		e.layout = name
+	}
+}
+
+// HTMLTemplateWithFuncs 添加自定义模板函数 与内置函数同名时覆盖内置函数
+func HTMLTemplateWithFuncs(funcs template.FuncMap) HTMLTemplateOption {
+	return func(e *HTMLTemplateEngine) {
+		for name, fn := range funcs {
+			e.funcs[name] = fn
+		}
+	}
+}
+
+// HTMLTemplateWithDevMode 开启开发模式 开启后每次渲染前检查模板文件的修改时间与大小 发生变化时重新解析所有模板
+// 修改模板后无需重启服务即可生效 由于每次渲染都会遍历文件系统 因此仅建议在开发环境中启用
+// Tips: embed.FS 中的文件在编译时就已确定 开发模式下应使用 os.DirFS
+func HTMLTemplateWithDevMode() HTMLTemplateOption {
+	return func(e *HTMLTemplateEngine) {
+		e.devMode = true
+	}
+}
+
+// NewHTMLTemplateEngine 创建基于 html/template 的模板引擎 并解析文件系统中的所有模板 模板语法错误时返回错误
+func NewHTMLTemplateEngine(fsys fs.FS, opts ...HTMLTemplateOption) (*HTMLTemplateEngine, error) {
+	e := &HTMLTemplateEngine{
+		fsys:   fsys,
+		shared: []string{"layouts/*.html", "partials/*.html"},
+		ext:    ".html",
+	}
+	e.funcs = template.FuncMap{"URLFor": e.urlFor}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	fingerprint := ""
+	if e.devMode {
+		var err error
+		if fingerprint, err = e.fingerprint(); err != nil {
+			return nil, err
+		}
+	}
+	set, err := e.parse(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	e.set.Store(set)
+	return e, nil
+}
+
+// setServer 关联HTTP服务器 用于 URLFor 函数
+func (e *HTMLTemplateEngine) setServer(server *HTTPServer) {
+	e.server.Store(server)
+}
+
+// Render 渲染给定名称的模板 并将结果写入w中 规则详见 HTMLTemplateEngine
+func (e *HTMLTemplateEngine) Render(w io.Writer, name string, data any) error {
+	set, err := e.templates()
+	if err != nil {
+		return err
+	}
+
+	if page, ok := set.pages[name]; ok {
+		if e.layout != "" {
+			return page.ExecuteTemplate(w, e.layout, data)
+		}
+		return page.ExecuteTemplate(w, name, data)
+	}
+	if set.shared.Lookup(name) != nil {
+		return set.shared.ExecuteTemplate(w, name, data)
+	}
+	return fmt.Errorf("%w %s", ErrTemplateNotFound, name)
+}
+
+// templates 返回已解析的模板集合 开发模式下模板文件变化时重新解析
+func (e *HTMLTemplateEngine) templates() (*templateSet, error) {
+	if !e.devMode {
+		return e.set.Load(), nil
+	}
+
+	fingerprint, err := e.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	if set := e.set.Load(); set.fingerprint == fingerprint {
+		return set, nil
+	}
+
+	// Tips: 加锁后再次检查 避免并发请求重复解析
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if set := e.set.Load(); set.fingerprint == fingerprint {
+		return set, nil
+	}
+	set, err := e.parse(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	e.set.Store(set)
+	return set, nil
+}
+
+// parse 解析文件系统中的所有模板 fingerprint为解析前计算的文件指纹 非开发模式下为空字符串
+func (e *HTMLTemplateEngine) parse(fingerprint string) (*templateSet, error) {
+	sharedNames, err := e.sharedNames()
+	if err != nil {
+		return nil, err
+	}
+	shared := template.New("").Funcs(e.funcs)
+	for _, name := range sharedNames {
+		if err = e.parseFile(shared, name); err != nil {
+			return nil, err
+		}
+	}
+	if e.layout != "" && shared.Lookup(e.layout) == nil {
+		return nil, fmt.Errorf("%w 布局模板 %s 未在共享模板中定义", ErrTemplateNotFound, e.layout)
+	}
+
+	pageNames, err := e.pageNames(sharedNames)
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]*template.Template, len(pageNames))
+	for _, name := range pageNames {
+		// Tips: 必须在执行共享模板之前克隆 html/template 不允许克隆已执行过的模板
+		page, err := shared.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if err = e.parseFile(page, name); err != nil {
+			return nil, err
+		}
+		pages[name] = page
+	}
+
+	return &templateSet{shared: shared, pages: pages, fingerprint: fingerprint}, nil
+}
+
+// parseFile 解析模板文件 并以文件路径作为模板名称加入到给定的模板集合中
+// Tips: 不使用 template.ParseFS 因为它以文件名作为模板名称 不同目录下的同名文件会相互覆盖
+func (e *HTMLTemplateEngine) parseFile(t *template.Template, name string) error {
+	content, err := fs.ReadFile(e.fsys, name)
+	if err != nil {
+		return err
+	}
+	_, err = t.New(name).Parse(string(content))
+	return err
+}
+
+// sharedNames 返回所有共享模板的文件路径 已排序且不重复
+func (e *HTMLTemplateEngine) sharedNames() ([]string, error) {
+	var names []string
+	for _, pattern := range e.shared {
+		matches, err := fs.Glob(e.fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, matches...)
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// pageNames 返回所有页面模板的文件路径 即扩展名匹配的非共享模板文件
+func (e *HTMLTemplateEngine) pageNames(sharedNames []string) ([]string, error) {
+	var names []string
+	err := fs.WalkDir(e.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && path.Ext(name) == e.ext && !slices.Contains(sharedNames, name) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+// fingerprint 计算所有文件的指纹 由文件路径 修改时间与大小组成 任一文件变化 新增或删除时指纹随之变化
+func (e *HTMLTemplateEngine) fingerprint() (string, error) {
+	var sb strings.Builder
+	err := fs.WalkDir(e.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d\n", name, info.ModTime().UnixNano(), info.Size())
+		return nil
+	})
+	return sb.String(), err
+}
+
+// urlFor 模板函数 URLFor 的实现 根据路由名称生成URL pairs为交替出现的路径参数名与值
+func (e *HTMLTemplateEngine) urlFor(name string, pairs ...any) (string, error) {
+	server := e.server.Load()
+	if server == nil {
+		return "", errors.New("web: 模板引擎未关联HTTP服务器 无法生成URL")
+	}
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("web: URLFor 的路径参数必须成对出现 路由名称 %s", name)
+	}
+
+	params := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		params[fmt.Sprint(pairs[i])] = fmt.Sprint(pairs[i+1])
+	}
+	return server.URLFor(name, params, nil)
+}
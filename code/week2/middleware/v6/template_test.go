@@ -0,0 +1,196 @@
+package web
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// templateFiles 用于测试的模板文件
+func templateFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html":   {Data: []byte(`<html><title>{{block "title" .}}default{{end}}</title><body>{{block "content" .}}{{end}}</body></html>`)},
+		"partials/nav.html":   {Data: []byte(`<nav><a href="{{URLFor "user" "id" .ID}}">{{.Name | upper}}</a></nav>`)},
+		"users/show.html":     {Data: []byte(`{{define "title"}}user{{end}}{{define "content"}}{{template "partials/nav.html" .}}<p>{{.Name}}</p>{{end}}`)},
+		"home.html":           {Data: []byte(`{{define "content"}}home{{end}}`)},
+		"admin/home.html":     {Data: []byte(`{{define "content"}}admin{{end}}`)},
+		"assets/readme.txt":   {Data: []byte(`not a template`)},
+		"partials/footer.tpl": {Data: []byte(`not a shared template`)},
+	}
+}
+
+// TestHTMLTemplateEngine 测试基于 html/template 的模板引擎
+func TestHTMLTemplateEngine(t *testing.T) {
+	engine, err := NewHTMLTemplateEngine(templateFiles(),
+		HTMLTemplateWithLayout("layouts/base.html"),
+		HTMLTemplateWithFuncs(template.FuncMap{"upper": strings.ToUpper}))
+	require.NoError(t, err)
+
+	s := NewHTTPServer(ServerWithTemplateEngine(engine))
+	s.GET("/users/:id", func(ctx *Context) {}).Name("user")
+
+	type user struct {
+		ID   int
+		Name string
+	}
+	testCases := []struct {
+		name     string
+		template string
+		data     any
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "layout",
+			template: "users/show.html",
+			data:     user{ID: 12, Name: "<tom>"},
+			want:     `<html><title>user</title><body><nav><a href="/users/12">&lt;TOM&gt;</a></nav><p>&lt;tom&gt;</p></body></html>`,
+		},
+		{
+			name:     "default block",
+			template: "home.html",
+			want:     `<html><title>default</title><body>home</body></html>`,
+		},
+		{
+			name:     "same template name in different pages",
+			template: "admin/home.html",
+			want:     `<html><title>default</title><body>admin</body></html>`,
+		},
+		{
+			name:     "partial",
+			template: "partials/nav.html",
+			data:     user{ID: 1, Name: "a"},
+			want:     `<nav><a href="/users/1">A</a></nav>`,
+		},
+		{
+			name:     "not found",
+			template: "missing.html",
+			wantErr:  ErrTemplateNotFound,
+		},
+		{
+			name:     "not a template",
+			template: "assets/readme.txt",
+			wantErr:  ErrTemplateNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := engine.Render(&buf, testCase.template, testCase.data)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.want, buf.String())
+		})
+	}
+}
+
+// TestHTMLTemplateEngine_Illegal_Case 测试创建模板引擎失败的情况
+func TestHTMLTemplateEngine_Illegal_Case(t *testing.T) {
+	_, err := NewHTMLTemplateEngine(fstest.MapFS{"home.html": {Data: []byte(`{{if}}`)}})
+	assert.Error(t, err)
+
+	upper := HTMLTemplateWithFuncs(template.FuncMap{"upper": strings.ToUpper})
+	_, err = NewHTMLTemplateEngine(templateFiles(), upper, HTMLTemplateWithLayout("layouts/missing.html"))
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	_, err = NewHTMLTemplateEngine(templateFiles(), HTMLTemplateWithShared("["))
+	assert.Error(t, err)
+
+	// 模板中使用了未定义的函数
+	_, err = NewHTMLTemplateEngine(templateFiles())
+	assert.Error(t, err)
+
+	// 未关联HTTP服务器时无法生成URL
+	engine, err := NewHTMLTemplateEngine(templateFiles(), upper)
+	require.NoError(t, err)
+	assert.Error(t, engine.Render(&bytes.Buffer{}, "partials/nav.html", map[string]any{"ID": 1}))
+}
+
+// TestHTMLTemplateEngine_DevMode 测试开发模式下模板文件变化时重新解析
+func TestHTMLTemplateEngine_DevMode(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "home.html")
+	require.NoError(t, os.WriteFile(page, []byte(`v1`), 0o644))
+
+	render := func(engine *HTMLTemplateEngine, name string) string {
+		var buf bytes.Buffer
+		require.NoError(t, engine.Render(&buf, name, nil))
+		return buf.String()
+	}
+
+	dev, err := NewHTMLTemplateEngine(os.DirFS(dir), HTMLTemplateWithDevMode())
+	require.NoError(t, err)
+	prod, err := NewHTMLTemplateEngine(os.DirFS(dir))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", render(dev, "home.html"))
+
+	// 修改时间精度较低的文件系统上 确保修改时间发生变化
+	require.NoError(t, os.WriteFile(page, []byte(`v2`), 0o644))
+	require.NoError(t, os.Chtimes(page, time.Now(), time.Now().Add(time.Second)))
+	assert.Equal(t, "v2", render(dev, "home.html"))
+	assert.Equal(t, "v1", render(prod, "home.html"))
+
+	// 新增模板
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "about.html"), []byte(`about`), 0o644))
+	assert.Equal(t, "about", render(dev, "about.html"))
+
+	// 模板语法错误时返回错误 修正后恢复
+	require.NoError(t, os.WriteFile(page, []byte(`{{if}}`), 0o644))
+	require.NoError(t, os.Chtimes(page, time.Now(), time.Now().Add(2*time.Second)))
+	assert.Error(t, dev.Render(&bytes.Buffer{}, "home.html", nil))
+	require.NoError(t, os.WriteFile(page, []byte(`v3`), 0o644))
+	require.NoError(t, os.Chtimes(page, time.Now(), time.Now().Add(3*time.Second)))
+	assert.Equal(t, "v3", render(dev, "home.html"))
+}
+
+// TestContext_Render 测试渲染模板并输出响应
+func TestContext_Render(t *testing.T) {
+	engine, err := NewHTMLTemplateEngine(fstest.MapFS{
+		"hello.html": {Data: []byte(`<p>hello {{.}}</p>`)},
+		"bad.html":   {Data: []byte(`<p>{{.Missing}}</p>`)},
+	})
+	require.NoError(t, err)
+
+	var seen string
+	s := NewHTTPServer(ServerWithTemplateEngine(engine), ServerWithMiddleware(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			seen = string(ctx.RespData)
+		}
+	}))
+	s.GET("/hello", func(ctx *Context) {
+		require.NoError(t, ctx.Render(http.StatusAccepted, "hello.html", "<tom>"))
+	})
+	s.GET("/bad", func(ctx *Context) {
+		assert.Error(t, ctx.Render(http.StatusOK, "bad.html", "tom"))
+	})
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, "<p>hello &lt;tom&gt;</p>", recorder.Body.String())
+	assert.Equal(t, "<p>hello &lt;tom&gt;</p>", seen)
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	// 渲染失败时不输出渲染到一半的页面
+	recorder = httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/bad", nil))
+	assert.Equal(t, "", recorder.Body.String())
+
+	// 未设置模板引擎
+	ctx := &Context{server: NewHTTPServer()}
+	assert.Error(t, ctx.Render(http.StatusOK, "hello.html", nil))
+}
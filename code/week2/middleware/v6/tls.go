@@ -0,0 +1,152 @@
+package web
+
+import (
+	"crypto/tls"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultCertCheckInterval StartTLS 检查证书文件是否发生变化的默认间隔
+const defaultCertCheckInterval = 10 * time.Second
+
+// ServerWithTLSConfig 本函数用于设置 HTTPServer 实例的TLS配置
+// 若配置中已经给出了证书(Certificates GetCertificate 或 GetConfigForClient) 则调用 StartTLS 时可以不传证书文件
+func ServerWithTLSConfig(config *tls.Config) Option {
+	return func(server *HTTPServer) {
+		server.httpServer().TLSConfig = config
+	}
+}
+
+// StartTLS 以HTTPS的方式启动WEB服务器 默认协商HTTP/2
+// 若给出了证书文件和私钥文件 则使用 CertReloader 加载证书 证书文件发生变化时无需重启即可生效
+// 调用 Shutdown 优雅退出后 本方法返回nil
+func (s *HTTPServer) StartTLS(addr string, certFile string, keyFile string) error {
+	srv := s.httpServer()
+	var config *tls.Config
+	if srv.TLSConfig == nil {
+		config = &tls.Config{}
+	} else {
+		// Tips: 克隆一份配置 避免修改使用者传入的配置
+		config = srv.TLSConfig.Clone()
+	}
+
+	if certFile != "" || keyFile != "" {
+		reloader, err := NewCertReloader(certFile, keyFile, defaultCertCheckInterval)
+		if err != nil {
+			return err
+		}
+		config.GetCertificate = reloader.GetCertificate
+	}
+	srv.TLSConfig = config
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	// Tips: 证书已经设置到TLSConfig上了 因此此处不需要再传证书文件
+	// Tips: 当 http.Server 的TLSNextProto为nil时 ServeTLS 会自动在NextProtos中加入h2 即默认协商HTTP/2
+	return s.serveListener(l, func(l net.Listener) error {
+		return srv.ServeTLS(l, "", "")
+	})
+}
+
+// CertReloader 证书热加载器
+// 每次TLS握手时 若距离上次检查已超过检查间隔 则检查证书文件和私钥文件的修改时间
+// 任意一个文件发生变化时重新加载证书 加载失败时继续使用旧证书 避免证书文件更新到一半时服务不可用
+type CertReloader struct {
+	certFile    string           // certFile 证书文件路径
+	keyFile     string           // keyFile 私钥文件路径
+	interval    time.Duration    // interval 检查文件是否发生变化的间隔 为0时每次握手都检查
+	mutex       sync.RWMutex     // mutex 保护以下字段
+	cert        *tls.Certificate // cert 当前使用的证书
+	certModTime time.Time        // certModTime 加载证书时证书文件的修改时间
+	keyModTime  time.Time        // keyModTime 加载证书时私钥文件的修改时间
+	lastCheck   time.Time        // lastCheck 上次检查文件的时间
+}
+
+// NewCertReloader 创建证书热加载器 并立即加载一次证书
+func NewCertReloader(certFile string, keyFile string, interval time.Duration) (*CertReloader, error) {
+	reloader := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+
+	err := reloader.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Reload 立即重新加载证书
+func (c *CertReloader) Reload() error {
+	certModTime, keyModTime, err := c.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cert = &cert
+	c.certModTime = certModTime
+	c.keyModTime = keyModTime
+	c.lastCheck = time.Now()
+	return nil
+}
+
+// GetCertificate 返回当前使用的证书 用于设置 tls.Config 的GetCertificate字段
+func (c *CertReloader) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if c.needCheck() {
+		c.checkAndReload()
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.cert, nil
+}
+
+// needCheck 判断距离上次检查是否已超过检查间隔
+func (c *CertReloader) needCheck() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return time.Since(c.lastCheck) >= c.interval
+}
+
+// checkAndReload 检查证书文件和私钥文件是否发生变化 若发生变化则重新加载证书
+func (c *CertReloader) checkAndReload() {
+	certModTime, keyModTime, err := c.modTimes()
+
+	c.mutex.Lock()
+	c.lastCheck = time.Now()
+	changed := err == nil && (!certModTime.Equal(c.certModTime) || !keyModTime.Equal(c.keyModTime))
+	c.mutex.Unlock()
+
+	if changed {
+		// 加载失败时继续使用旧证书 下次检查时会再次尝试加载
+		_ = c.Reload()
+	}
+}
+
+// modTimes 获取证书文件和私钥文件的修改时间
+func (c *CertReloader) modTimes() (certModTime time.Time, keyModTime time.Time, err error) {
+	certInfo, err := os.Stat(c.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	keyInfo, err := os.Stat(c.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
@@ -0,0 +1,151 @@
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert 生成一个自签名证书 并将证书和私钥写入给定的文件
+// commonName用于区分不同的证书
+func writeSelfSignedCert(t *testing.T, certFile string, keyFile string, commonName string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600))
+}
+
+// getTLS 发起一个HTTPS请求 每次请求都会重新握手 返回响应协议 服务端证书的CommonName和响应体
+func getTLS(t *testing.T, url string) (proto string, commonName string, body string) {
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+			DisableKeepAlives: true,
+		},
+	}
+
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		resp, err = client.Get(url)
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.Proto, resp.TLS.PeerCertificates[0].Subject.CommonName, string(data)
+}
+
+// TestHTTPServer_StartTLS 测试以HTTPS启动服务 且默认协商HTTP/2
+func TestHTTPServer_StartTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeSelfSignedCert(t, certFile, keyFile, "web-test")
+
+	s := NewHTTPServer()
+	s.GET("/hello", func(ctx *Context) {
+		ctx.RespData = []byte("hello " + ctx.Req.Proto)
+	})
+
+	addr := freeAddr(t)
+	go func() {
+		_ = s.StartTLS(addr, certFile, keyFile)
+	}()
+	defer s.Shutdown(context.Background())
+
+	proto, commonName, body := getTLS(t, "https://"+addr+"/hello")
+	assert.Equal(t, "HTTP/2.0", proto)
+	assert.Equal(t, "web-test", commonName)
+	assert.Equal(t, "hello HTTP/2.0", body)
+}
+
+// TestCertReloader 测试证书文件发生变化后 无需重启即可使用新证书
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeSelfSignedCert(t, certFile, keyFile, "old-cert")
+
+	reloader, err := NewCertReloader(certFile, keyFile, 0)
+	require.NoError(t, err)
+
+	s := NewHTTPServer(ServerWithTLSConfig(&tls.Config{
+		GetCertificate: reloader.GetCertificate,
+	}))
+	s.GET("/", func(ctx *Context) {
+		ctx.RespData = []byte("ok")
+	})
+
+	addr := freeAddr(t)
+	go func() {
+		_ = s.StartTLS(addr, "", "")
+	}()
+	defer s.Shutdown(context.Background())
+
+	_, commonName, _ := getTLS(t, "https://"+addr+"/")
+	assert.Equal(t, "old-cert", commonName)
+
+	// 替换证书文件 并修改文件的修改时间 避免文件系统的时间精度导致修改时间不变
+	writeSelfSignedCert(t, certFile, keyFile, "new-cert")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	_, commonName, _ = getTLS(t, "https://"+addr+"/")
+	assert.Equal(t, "new-cert", commonName)
+
+	// 证书文件损坏时 继续使用旧证书
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0600))
+	broken := future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, broken, broken))
+
+	_, commonName, _ = getTLS(t, "https://"+addr+"/")
+	assert.Equal(t, "new-cert", commonName)
+}
+
+// TestNewCertReloader_error 测试证书文件不存在时创建证书热加载器失败
+func TestNewCertReloader_error(t *testing.T) {
+	_, err := NewCertReloader("not-exist.pem", "not-exist.key", time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,693 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidValidationRule 结构体标签中的校验规则非法 例如使用了未注册的规则 min max len 的参数不是数字等
+// 这属于编码错误而非请求错误 因此 Validate 返回的该错误不是 ValidationErrors
+var ErrInvalidValidationRule = errors.New("web: 非法校验规则")
+
+// ValidationField 校验规则的入参
+type ValidationField struct {
+	Value  reflect.Value // Value 字段的值 指针类型的字段已被解引用
+	Param  string        // Param 规则的参数 例如 min=1 的参数为 "1" 无参数时为空字符串
+	Parent reflect.Value // Parent 字段所在的结构体 用于跨字段校验
+}
+
+// ValidationRule 校验规则 返回字段是否满足规则
+type ValidationRule func(field ValidationField) bool
+
+// validationRulesMutex 保护validationRules
+var validationRulesMutex sync.RWMutex
+
+// validationRules 规则名到校验规则的映射
+// 内置规则:
+// 1. required: 值不能为零值 切片与map的长度不能为0 指针不能为nil
+// 2. omitempty: 值为零值时跳过该字段上的其他规则
+// 3. min max len: 字符串的字符数 切片与map的长度 或数字的值 不小于 不大于 等于参数
+// 4. oneof: 值为参数中以空格分隔的值之一 例如 oneof=red green blue
+// 5. email url: 值为合法的邮箱地址 URL
+// 6. eqfield nefield gtfield gtefield ltfield ltefield: 与同一结构体中的另一个字段比较 参数为字段名 例如 eqfield=Password
+// Tips: min max len 的参数需要在解析标签时转换为数字 因此不在此处 详见 numberRules
+var validationRules = map[string]ValidationRule{
+	"required": func(field ValidationField) bool { return !isEmptyField(field.Value) },
+	"oneof":    isOneOf,
+	"email":    isEmail,
+	"url":      isURL,
+	"eqfield":  fieldComparator(func(c int) bool { return c == 0 }),
+	"nefield":  fieldComparator(func(c int) bool { return c != 0 }),
+	"gtfield":  fieldComparator(func(c int) bool { return c > 0 }),
+	"gtefield": fieldComparator(func(c int) bool { return c >= 0 }),
+	"ltfield":  fieldComparator(func(c int) bool { return c < 0 }),
+	"ltefield": fieldComparator(func(c int) bool { return c <= 0 }),
+}
+
+// numberRules 参数为数字的内置规则 解析标签时将参数转换为数字 并校验字段类型能否度量 详见 measure
+// key为规则名 value根据字段的度量与参数判断是否满足规则
+var numberRules = map[string]func(measure float64, param float64) bool{
+	"min": func(measure float64, param float64) bool { return measure >= param },
+	"max": func(measure float64, param float64) bool { return measure <= param },
+	"len": func(measure float64, param float64) bool { return measure == param },
+}
+
+// crossFieldRules 与同一结构体中另一个字段比较的内置规则 解析标签时校验参数引用的字段是否存在
+var crossFieldRules = map[string]bool{
+	"eqfield":  true,
+	"nefield":  true,
+	"gtfield":  true,
+	"gtefield": true,
+	"ltfield":  true,
+	"ltefield": true,
+}
+
+// kindRules 只适用于部分字段类型的内置规则 解析标签时校验字段类型 避免校验时才发现规则用错了字段
+// key为规则名 value判断给定类型的字段能否使用该规则
+var kindRules = map[string]func(kind reflect.Kind) bool{
+	"oneof": isScalar,
+	"email": func(kind reflect.Kind) bool { return kind == reflect.String },
+	"url":   func(kind reflect.Kind) bool { return kind == reflect.String },
+}
+
+// RegisterValidationRule 注册自定义校验规则 同名规则会被覆盖 例如:
+//
+//	RegisterValidationRule("even", func(field ValidationField) bool {
+//		return field.Value.Int()%2 == 0
+//	})
+//	RegisterValidationMessage("zh", "even", "{field}必须是偶数")
+//
+// 注册后即可在标签中使用 例如 validate:"required,even"
+// Tips: 覆盖内置规则后 不再对该规则的参数做内置的校验 例如覆盖min后 min的参数不必为数字
+func RegisterValidationRule(name string, rule ValidationRule) {
+	validationRulesMutex.Lock()
+	defer validationRulesMutex.Unlock()
+	validationRules[name] = rule
+	delete(numberRules, name)
+	delete(crossFieldRules, name)
+	delete(kindRules, name)
+
+	// 已解析的结构体可能引用了该规则 清空缓存使其重新解析
+	validateFieldsCache.Range(func(key, _ any) bool {
+		validateFieldsCache.Delete(key)
+		return true
+	})
+}
+
+// DefaultValidationLanguage 未指定语言或指定的语言没有对应的消息时使用的语言
+const DefaultValidationLanguage = "zh"
+
+// validationMessagesMutex 保护validationMessages
+var validationMessagesMutex sync.RWMutex
+
+// validationMessages 语言到消息模板的映射 消息模板的key为规则名
+// min max len 用于字符串 切片与map时 key为规则名加上"_len"后缀 以区分数值与长度
+// 模板中的 {field} {param} {rule} 分别替换为字段名 规则参数 规则名
+// key为"title"的模板为校验失败响应的标题 key为""的模板为没有对应模板的规则的兜底模板
+var validationMessages = map[string]map[string]string{
+	"zh": {
+		"":         "{field}未通过{rule}校验",
+		"title":    "请求参数校验失败",
+		"required": "{field}为必填项",
+		"min":      "{field}不能小于{param}",
+		"min_len":  "{field}的长度不能小于{param}",
+		"max":      "{field}不能大于{param}",
+		"max_len":  "{field}的长度不能大于{param}",
+		"len":      "{field}必须等于{param}",
+		"len_len":  "{field}的长度必须为{param}",
+		"oneof":    "{field}必须是[{param}]中的一个",
+		"email":    "{field}必须是合法的邮箱地址",
+		"url":      "{field}必须是合法的URL",
+		"eqfield":  "{field}必须与{param}相等",
+		"nefield":  "{field}不能与{param}相等",
+		"gtfield":  "{field}必须大于{param}",
+		"gtefield": "{field}必须大于或等于{param}",
+		"ltfield":  "{field}必须小于{param}",
+		"ltefield": "{field}必须小于或等于{param}",
+	},
+	"en": {
+		"":         "{field} failed on the {rule} rule",
+		"title":    "Request validation failed",
+		"required": "{field} is required",
+		"min":      "{field} must be at least {param}",
+		"min_len":  "{field} must be at least {param} characters or items long",
+		"max":      "{field} must be at most {param}",
+		"max_len":  "{field} must be at most {param} characters or items long",
+		"len":      "{field} must be {param}",
+		"len_len":  "{field} must be exactly {param} characters or items long",
+		"oneof":    "{field} must be one of [{param}]",
+		"email":    "{field} must be a valid email address",
+		"url":      "{field} must be a valid URL",
+		"eqfield":  "{field} must be equal to {param}",
+		"nefield":  "{field} must not be equal to {param}",
+		"gtfield":  "{field} must be greater than {param}",
+		"gtefield": "{field} must be greater than or equal to {param}",
+		"ltfield":  "{field} must be less than {param}",
+		"ltefield": "{field} must be less than or equal to {param}",
+	},
+}
+
+// RegisterValidationMessage 注册给定语言下给定规则的消息模板 同名模板会被覆盖 模板的格式详见 validationMessages
+// 可用于为自定义规则注册消息 或为新的语言注册全部消息
+func RegisterValidationMessage(lang string, key string, template string) {
+	validationMessagesMutex.Lock()
+	defer validationMessagesMutex.Unlock()
+
+	lang = strings.ToLower(lang)
+	if validationMessages[lang] == nil {
+		validationMessages[lang] = map[string]string{}
+	}
+	validationMessages[lang][key] = template
+}
+
+// validationMessage 查找给定语言下给定key的消息模板
+// 找不到时依次查找默认语言下的模板 给定语言下的兜底模板 默认语言下的兜底模板
+func validationMessage(lang string, key string) string {
+	validationMessagesMutex.RLock()
+	defer validationMessagesMutex.RUnlock()
+
+	lang = strings.ToLower(lang)
+	for _, k := range []string{key, ""} {
+		for _, l := range []string{lang, DefaultValidationLanguage} {
+			if template, ok := validationMessages[l][k]; ok {
+				return template
+			}
+		}
+	}
+	return ""
+}
+
+// ValidationError 某个字段未通过某条规则时的错误
+type ValidationError struct {
+	Field    string // Field 字段在结构体中的路径 例如 Items[0].Name
+	Name     string // Name 字段对外的名称 优先使用json标签 其次使用绑定来源的标签 最后使用字段名 例如 items[0].name
+	Rule     string // Rule 未通过的规则名
+	Param    string // Param 规则的参数
+	Value    any    // Value 字段的值
+	byLength bool   // byLength 字段是否按长度度量 即字符串 切片 数组或map 用于区分min max len的消息
+}
+
+// Translate 返回给定语言的错误消息 语言不存在时使用默认语言
+func (e *ValidationError) Translate(lang string) string {
+	key := e.Rule
+	if e.byLength && (key == "min" || key == "max" || key == "len") {
+		key += "_len"
+	}
+
+	template := validationMessage(lang, key)
+	return strings.NewReplacer("{field}", e.Name, "{param}", e.Param, "{rule}", e.Rule).Replace(template)
+}
+
+// Error 返回默认语言的错误消息
+func (e *ValidationError) Error() string {
+	return "web校验错误: " + e.Translate(DefaultValidationLanguage)
+}
+
+// ValidationErrors 校验时所有字段的错误 校验不会在第一个错误时停止 而是收集所有字段的错误
+type ValidationErrors []*ValidationError
+
+// Error 返回所有错误消息 每个错误占一行
+func (e ValidationErrors) Error() string {
+	return joinErrors(e)
+}
+
+// Unwrap 返回所有错误 用于支持 errors.Is 与 errors.As
+func (e ValidationErrors) Unwrap() []error {
+	return unwrapErrors(e)
+}
+
+// ValidationProblem 校验失败时的响应体 格式参考 RFC 7807 (application/problem+json)
+type ValidationProblem struct {
+	Type   string                   `json:"type"`   // Type 问题类型 固定为 about:blank
+	Title  string                   `json:"title"`  // Title 问题标题
+	Status int                      `json:"status"` // Status 响应码 固定为400
+	Errors []ValidationProblemError `json:"errors"` // Errors 所有字段的错误
+}
+
+// ValidationProblemError 校验失败时响应体中某个字段的错误
+type ValidationProblemError struct {
+	Field   string `json:"field"`           // Field 字段对外的名称
+	Rule    string `json:"rule"`            // Rule 未通过的规则名
+	Param   string `json:"param,omitempty"` // Param 规则的参数
+	Message string `json:"message"`         // Message 给定语言的错误消息
+}
+
+// Problem 将校验错误转换为给定语言的响应体
+func (e ValidationErrors) Problem(lang string) ValidationProblem {
+	problem := ValidationProblem{
+		Type:   "about:blank",
+		Title:  validationMessage(lang, "title"),
+		Status: http.StatusBadRequest,
+		Errors: make([]ValidationProblemError, 0, len(e)),
+	}
+	for _, err := range e {
+		problem.Errors = append(problem.Errors, ValidationProblemError{
+			Field:   err.Name,
+			Rule:    err.Rule,
+			Param:   err.Param,
+			Message: err.Translate(lang),
+		})
+	}
+	return problem
+}
+
+// RespValidationErrors 以JSON格式输出校验失败的响应 响应码为400
+// 消息的语言根据请求头 Accept-Language 选择 没有匹配的语言时使用默认语言 例如:
+//
+//	if err := ctx.Bind(&req); err != nil {
+//		var errs ValidationErrors
+//		if errors.As(err, &errs) {
+//			_ = ctx.RespValidationErrors(errs)
+//			return
+//		}
+//	}
+func (c *Context) RespValidationErrors(errs ValidationErrors) error {
+	return c.RespJSON(http.StatusBadRequest, errs.Problem(negotiateLanguage(c.Req.Header.Get("Accept-Language"))))
+}
+
+// negotiateLanguage 根据请求头 Accept-Language 选择已注册消息的语言 按权重从高到低匹配 先精确匹配 再匹配主语言
+// 例如 zh-CN 在没有注册 zh-cn 时匹配 zh
+func negotiateLanguage(acceptLanguage string) string {
+	candidates := slices.DeleteFunc(parseQualityList(acceptLanguage), func(c qualityValue) bool {
+		return c.q <= 0
+	})
+	// Tips: 稳定排序 权重相同时保持请求头中的顺序
+	slices.SortStableFunc(candidates, func(a, b qualityValue) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	validationMessagesMutex.RLock()
+	defer validationMessagesMutex.RUnlock()
+	for _, c := range candidates {
+		if _, ok := validationMessages[c.value]; ok {
+			return c.value
+		}
+		if primary, _, ok := strings.Cut(c.value, "-"); ok {
+			if _, ok := validationMessages[primary]; ok {
+				return primary
+			}
+		}
+	}
+	return DefaultValidationLanguage
+}
+
+// Validate 根据结构体字段上的 validate 标签校验给定的结构体实例 target为结构体或指向结构体的指针 其他类型不做校验
+// 规则之间以","分隔 规则的参数跟在"="之后 例如 validate:"required,min=1,max=64,email,oneof=a b"
+// 嵌套的结构体 结构体指针 以及结构体(指针)的切片与数组会被递归校验 validate:"-" 的字段不做校验
+// 所有字段的错误都会被收集后以 ValidationErrors 返回 Bind 与 BindJSON 在绑定成功后会自动调用本函数
+// 标签中使用了未注册的规则 或内置规则的参数非法时 返回包装了 ErrInvalidValidationRule 的错误 而非 ValidationErrors
+// Tips: 每个结构体类型的标签只解析与校验一次 详见 validateFieldsOf
+func Validate(target any) error {
+	v := reflect.ValueOf(target)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs ValidationErrors
+	if err := validateStruct(v, "", "", &errs); err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateStruct 校验结构体中的所有字段 错误追加到errs中 path与namePath为结构体自身的路径
+// 标签中的规则非法时返回错误
+func validateStruct(v reflect.Value, path string, namePath string, errs *ValidationErrors) error {
+	fields, err := validateFieldsOf(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		value := v.Field(field.index)
+		fieldPath, fieldName := joinFieldPath(path, field.name), joinFieldPath(namePath, field.externalName)
+		if field.embedded {
+			// 内嵌结构体的字段视为外层结构体的字段 路径中不包含内嵌结构体的名称
+			fieldPath, fieldName = path, namePath
+		}
+
+		deref := value
+		for deref.Kind() == reflect.Pointer && !deref.IsNil() {
+			deref = deref.Elem()
+		}
+
+		for _, rule := range field.rules {
+			// 值为零值时 omitempty之后的规则 以及除required之外的规则都无需校验
+			if rule.name == "omitempty" {
+				if isEmptyField(value) {
+					break
+				}
+				continue
+			}
+
+			// required只判断指针是否为nil 其他规则校验指针指向的值 指针为nil时无需校验
+			ruleValue := deref
+			if rule.name == "required" {
+				ruleValue = value
+			} else if deref.Kind() == reflect.Pointer {
+				continue
+			}
+
+			if !rule.rule(ValidationField{Value: ruleValue, Param: rule.param, Parent: v}) {
+				*errs = append(*errs, &ValidationError{
+					Field:    fieldPath,
+					Name:     fieldName,
+					Rule:     rule.name,
+					Param:    rule.param,
+					Value:    value.Interface(),
+					byLength: isMeasuredByLength(deref),
+				})
+			}
+		}
+
+		if err := validateNested(deref, fieldPath, fieldName, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNested 递归校验结构体 以及结构体(指针)的切片与数组
+func validateNested(v reflect.Value, path string, namePath string, errs *ValidationErrors) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() != timeType {
+			return validateStruct(v, path, namePath, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			for elem.Kind() == reflect.Pointer && !elem.IsNil() {
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.Struct {
+				continue
+			}
+			if err := validateNested(elem, fmt.Sprintf("%s[%d]", path, i), fmt.Sprintf("%s[%d]", namePath, i), errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// joinFieldPath 拼接字段路径
+func joinFieldPath(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// validateRuleSpec 标签中的一条规则
+type validateRuleSpec struct {
+	name  string         // name 规则名
+	param string         // param 规则的参数
+	rule  ValidationRule // rule 解析标签时查找到的校验规则 omitempty的rule为nil
+}
+
+// validateField 需要校验的字段 由结构体类型解析得到 并按结构体类型缓存
+type validateField struct {
+	index        int                // index 字段的索引
+	name         string             // name 字段名
+	externalName string             // externalName 字段对外的名称
+	embedded     bool               // embedded 是否为内嵌字段
+	rules        []validateRuleSpec // rules 字段上的所有规则 按标签中的顺序排列
+}
+
+// validateFields 结构体类型解析的结果
+type validateFields struct {
+	fields []validateField // fields 需要校验的字段
+	err    error           // err 标签中的规则非法时的错误
+}
+
+// validateFieldsCache 结构体类型到 validateFields 的缓存 避免每次校验都解析结构体标签
+var validateFieldsCache sync.Map
+
+// validateFieldsOf 解析给定结构体类型中所有需要校验的字段 没有规则但可能包含嵌套结构体的字段同样会被返回
+// 解析时查找每条规则 并校验规则的参数 规则非法时返回错误 结果与错误均按结构体类型缓存
+func validateFieldsOf(typ reflect.Type) ([]validateField, error) {
+	if cached, ok := validateFieldsCache.Load(typ); ok {
+		res := cached.(validateFields)
+		return res.fields, res.err
+	}
+
+	var res validateFields
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		tag := structField.Tag.Get("validate")
+		if !structField.IsExported() || tag == "-" {
+			continue
+		}
+
+		field := validateField{
+			index:        i,
+			name:         structField.Name,
+			externalName: externalFieldName(structField),
+			embedded:     structField.Anonymous,
+		}
+		if tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				name, param, _ := strings.Cut(rule, "=")
+				spec := validateRuleSpec{name: strings.TrimSpace(name), param: param}
+				var err error
+				spec.rule, err = resolveRule(typ, structField, spec)
+				if err != nil {
+					res.err = err
+					break
+				}
+				field.rules = append(field.rules, spec)
+			}
+		}
+		if res.err != nil {
+			res.fields = nil
+			break
+		}
+		res.fields = append(res.fields, field)
+	}
+
+	validateFieldsCache.Store(typ, res)
+	return res.fields, res.err
+}
+
+// resolveRule 查找字段上给定规则对应的校验规则 并校验规则的参数
+// 1. 规则必须已注册
+// 2. min max len 的参数必须为数字 且字段类型能够度量 详见 measure
+// 3. 跨字段规则引用的字段必须存在于同一结构体中
+// 4. oneof email url 的字段类型必须适用于该规则 详见 kindRules
+func resolveRule(parent reflect.Type, field reflect.StructField, spec validateRuleSpec) (ValidationRule, error) {
+	if spec.name == "omitempty" {
+		return nil, nil
+	}
+
+	validationRulesMutex.RLock()
+	rule, ok := validationRules[spec.name]
+	cmp, isNumberRule := numberRules[spec.name]
+	isCrossFieldRule := crossFieldRules[spec.name]
+	acceptKind, isKindRule := kindRules[spec.name]
+	validationRulesMutex.RUnlock()
+
+	typ := field.Type
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case isNumberRule:
+		param, err := strconv.ParseFloat(spec.param, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w,规则的参数必须为数字.字段 %s.%s 规则 %s=%s", ErrInvalidValidationRule, parent, field.Name, spec.name, spec.param)
+		}
+
+		if !isMeasurable(typ.Kind()) {
+			return nil, fmt.Errorf("%w,类型 %s 不支持 min max len 规则.字段 %s.%s", ErrInvalidValidationRule, typ, parent, field.Name)
+		}
+		return func(field ValidationField) bool { return cmp(measure(field.Value), param) }, nil
+	case !ok:
+		return nil, fmt.Errorf("%w,未注册的规则 %s.字段 %s.%s", ErrInvalidValidationRule, spec.name, parent, field.Name)
+	case isCrossFieldRule:
+		if _, ok := parent.FieldByName(spec.param); !ok {
+			return nil, fmt.Errorf("%w,跨字段规则引用的字段 %s 不存在.字段 %s.%s", ErrInvalidValidationRule, spec.param, parent, field.Name)
+		}
+	case isKindRule:
+		if !acceptKind(typ.Kind()) {
+			return nil, fmt.Errorf("%w,类型 %s 不支持 %s 规则.字段 %s.%s", ErrInvalidValidationRule, typ, spec.name, parent, field.Name)
+		}
+	}
+	return rule, nil
+}
+
+// externalFieldName 返回字段对外的名称 优先使用json标签 其次使用绑定来源的标签 最后使用字段名
+func externalFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	for _, source := range bindSources {
+		if key := field.Tag.Get(source); key != "" && key != "-" {
+			return key
+		}
+	}
+	return field.Name
+}
+
+// isEmptyField 判断字段是否为空 切片与map的长度为0时同样视为空
+func isEmptyField(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Invalid:
+		return true
+	}
+	return v.IsZero()
+}
+
+// isMeasuredByLength 判断字段是否按长度度量
+func isMeasuredByLength(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// isMeasurable 判断给定类型的值能否度量 即能否使用 min max len 规则
+func isMeasurable(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isScalar 判断给定类型的值是否为字符串 数字或布尔值 即能否使用 oneof 规则
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// measure 返回字段的度量 字符串为字符数 切片 数组与map为长度 数字为值本身
+// 其他类型返回0 使用 min max len 规则的字段类型在解析标签时已经校验过 详见 resolveRule
+func measure(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String()))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return 0
+}
+
+// isOneOf 判断值是否为参数中以空格分隔的值之一
+// 使用 oneof 规则的字段类型在解析标签时已经校验过 只会是字符串 数字或布尔值 详见 resolveRule
+func isOneOf(field ValidationField) bool {
+	value := fmt.Sprint(field.Value.Interface())
+	return slices.Contains(strings.Fields(field.Param), value)
+}
+
+// isEmail 判断字符串是否为合法的邮箱地址 不允许包含显示名 例如 "Tom <tom@example.com>"
+func isEmail(field ValidationField) bool {
+	value := field.Value.String()
+	addr, err := mail.ParseAddress(value)
+	return err == nil && addr.Address == value
+}
+
+// isURL 判断字符串是否为包含协议与主机的合法URL
+func isURL(field ValidationField) bool {
+	u, err := url.ParseRequestURI(field.Value.String())
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// fieldComparator 创建与同一结构体中另一个字段比较的规则 accept根据比较结果(-1 0 1)判断是否满足规则
+func fieldComparator(accept func(c int) bool) ValidationRule {
+	return func(field ValidationField) bool {
+		// Tips: 引用的字段是否存在在解析标签时已经校验过 详见 resolveRule
+		other := field.Parent.FieldByName(field.Param)
+		if !other.IsValid() {
+			return false
+		}
+		for other.Kind() == reflect.Pointer {
+			if other.IsNil() {
+				return false
+			}
+			other = other.Elem()
+		}
+		return accept(compareValues(field.Value, other))
+	}
+}
+
+// compareValues 比较两个值 支持数字 字符串与 time.Time 其他类型仅比较是否相等(相等为0 否则为1)
+func compareValues(a reflect.Value, b reflect.Value) int {
+	if a.Type() == timeType && b.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	}
+
+	switch {
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case isNumber(a) && isNumber(b):
+		x, y := measure(a), measure(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+
+	if reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return 0
+	}
+	return 1
+}
+
+// isNumber 判断值是否为数字
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
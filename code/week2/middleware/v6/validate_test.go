@@ -0,0 +1,473 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validateItem 用于测试切片中结构体的校验
+type validateItem struct {
+	Name  string `json:"name" validate:"required"`
+	Count int    `json:"count" validate:"min=1"`
+}
+
+// ValidatePage 用于测试内嵌结构体的校验
+type ValidatePage struct {
+	Size int `query:"size" validate:"omitempty,max=100"`
+}
+
+// validateIllegalItem 用于测试嵌套结构体中的非法规则
+type validateIllegalItem struct {
+	Count int `validate:"len=a"`
+}
+
+// validateRequest 用于测试校验的请求结构体
+type validateRequest struct {
+	Name     string          `json:"name" validate:"required,min=2,max=8"`
+	Age      int             `json:"age" validate:"min=18,max=130"`
+	Email    string          `json:"email" validate:"omitempty,email"`
+	Homepage *string         `json:"homepage" validate:"omitempty,url"`
+	Color    string          `json:"color" validate:"oneof=red green blue"`
+	Tags     []string        `json:"tags" validate:"required,max=2"`
+	Password string          `json:"password"`
+	Confirm  string          `json:"confirm" validate:"eqfield=Password"`
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end" validate:"gtfield=Start"`
+	Items    []*validateItem `json:"items"`
+	Owner    *validateItem   `json:"owner"`
+	Ignored  string          `json:"-" validate:"-"`
+	ValidatePage
+}
+
+// validRequest 返回一个满足所有规则的请求
+func validRequest() validateRequest {
+	return validateRequest{
+		Name:     "Tom",
+		Age:      18,
+		Color:    "red",
+		Tags:     []string{"a"},
+		Password: "secret",
+		Confirm:  "secret",
+		Start:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// TestValidate 测试根据结构体标签校验
+func TestValidate(t *testing.T) {
+	homepage := "not a url"
+	testCases := []struct {
+		name     string
+		modify   func(req *validateRequest)
+		wantErrs []string
+	}{
+		{
+			name:   "valid",
+			modify: func(req *validateRequest) {},
+		},
+		{
+			name: "required",
+			modify: func(req *validateRequest) {
+				req.Name = ""
+				req.Tags = []string{}
+			},
+			wantErrs: []string{"name:required", "name:min", "tags:required"},
+		},
+		{
+			name: "length counts characters",
+			modify: func(req *validateRequest) {
+				req.Name = "张三"
+			},
+		},
+		{
+			name: "min max",
+			modify: func(req *validateRequest) {
+				req.Name = "Christopher"
+				req.Age = 17
+				req.Tags = []string{"a", "b", "c"}
+			},
+			wantErrs: []string{"name:max", "age:min", "tags:max"},
+		},
+		{
+			name: "omitempty",
+			modify: func(req *validateRequest) {
+				req.Email = "tom"
+				req.Homepage = &homepage
+			},
+			wantErrs: []string{"email:email", "homepage:url"},
+		},
+		{
+			name: "oneof",
+			modify: func(req *validateRequest) {
+				req.Color = "black"
+			},
+			wantErrs: []string{"color:oneof"},
+		},
+		{
+			name: "cross field",
+			modify: func(req *validateRequest) {
+				req.Confirm = "other"
+				req.End = req.Start
+			},
+			wantErrs: []string{"confirm:eqfield", "end:gtfield"},
+		},
+		{
+			name: "nested",
+			modify: func(req *validateRequest) {
+				req.Items = []*validateItem{{Name: "a", Count: 1}, nil, {Count: 0}}
+				req.Owner = &validateItem{Name: "b"}
+			},
+			wantErrs: []string{"items[2].name:required", "items[2].count:min", "owner.count:min"},
+		},
+		{
+			name: "embedded",
+			modify: func(req *validateRequest) {
+				req.Size = 101
+			},
+			wantErrs: []string{"size:max"},
+		},
+		{
+			name: "ignored",
+			modify: func(req *validateRequest) {
+				req.Ignored = "x"
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req := validRequest()
+			testCase.modify(&req)
+			err := Validate(&req)
+			if len(testCase.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var errs ValidationErrors
+			require.ErrorAs(t, err, &errs)
+			got := make([]string, 0, len(errs))
+			for _, e := range errs {
+				got = append(got, e.Name+":"+e.Rule)
+			}
+			assert.Equal(t, testCase.wantErrs, got)
+		})
+	}
+}
+
+// TestValidate_Field 测试错误中的字段路径与值
+func TestValidate_Field(t *testing.T) {
+	req := validRequest()
+	req.Items = []*validateItem{{Name: "a"}}
+	var errs ValidationErrors
+	require.ErrorAs(t, Validate(req), &errs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Items[0].Count", errs[0].Field)
+	assert.Equal(t, "items[0].count", errs[0].Name)
+	assert.Equal(t, "1", errs[0].Param)
+	assert.Equal(t, 0, errs[0].Value)
+
+	var target *ValidationError
+	assert.ErrorAs(t, error(errs), &target)
+	assert.Equal(t, "web校验错误: items[0].count不能小于1", errs.Error())
+}
+
+// TestValidate_Illegal_Case 测试不做校验的实例与非法的规则
+func TestValidate_Illegal_Case(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate((*validateRequest)(nil)))
+	assert.NoError(t, Validate(map[string]string{}))
+
+	testCases := []struct {
+		name   string
+		target any
+	}{
+		{
+			name: "unknown rule",
+			target: struct {
+				Name string `validate:"unknown"`
+			}{},
+		},
+		{
+			name: "empty rule",
+			target: struct {
+				Name string `validate:"required,"`
+			}{},
+		},
+		{
+			name: "number param",
+			target: struct {
+				Name string `validate:"min=x"`
+			}{},
+		},
+		{
+			name: "not measurable",
+			target: struct {
+				Start time.Time `validate:"max=1"`
+			}{},
+		},
+		{
+			name: "missing field",
+			target: struct {
+				Name string `validate:"eqfield=Missing"`
+			}{},
+		},
+		{
+			// 切片不会按元素逐个比较 而是在解析标签时拒绝
+			name: "oneof on slice",
+			target: struct {
+				Colors []string `validate:"oneof=red green blue"`
+			}{Colors: []string{"red"}},
+		},
+		{
+			name: "email on int",
+			target: struct {
+				Email int `validate:"email"`
+			}{},
+		},
+		{
+			name: "url on struct",
+			target: struct {
+				Homepage *time.Time `validate:"omitempty,url"`
+			}{},
+		},
+		{
+			// 嵌套结构体中的非法规则 即使外层结构体的规则合法也会返回错误
+			name: "nested",
+			target: struct {
+				Name  string `validate:"required"`
+				Items []validateIllegalItem
+			}{Name: "a", Items: []validateIllegalItem{{}}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// 非法规则属于编码错误 以错误返回而非panic 且不是 ValidationErrors
+			var err error
+			assert.NotPanics(t, func() {
+				err = Validate(testCase.target)
+			})
+			assert.ErrorIs(t, err, ErrInvalidValidationRule)
+			var errs ValidationErrors
+			assert.False(t, errors.As(err, &errs))
+
+			// 解析结果被缓存 再次校验返回同样的错误
+			assert.Equal(t, err, Validate(testCase.target))
+		})
+	}
+}
+
+// TestValidate_OneOf 测试 oneof 规则用于数字 布尔值与指针字段
+func TestValidate_OneOf(t *testing.T) {
+	type oneOfRequest struct {
+		Level  int   `validate:"oneof=1 2 3"`
+		Active bool  `validate:"oneof=true"`
+		Page   *uint `validate:"omitempty,oneof=10 20"`
+	}
+	page, otherPage := uint(10), uint(15)
+
+	testCases := []struct {
+		name     string
+		target   oneOfRequest
+		wantErrs []string
+	}{
+		{
+			name:   "valid",
+			target: oneOfRequest{Level: 2, Active: true, Page: &page},
+		},
+		{
+			name:   "nil pointer",
+			target: oneOfRequest{Level: 1, Active: true},
+		},
+		{
+			name:     "invalid",
+			target:   oneOfRequest{Level: 4, Active: false, Page: &otherPage},
+			wantErrs: []string{"Level:oneof", "Active:oneof", "Page:oneof"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Validate(testCase.target)
+			if len(testCase.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var errs ValidationErrors
+			require.ErrorAs(t, err, &errs)
+			got := make([]string, 0, len(errs))
+			for _, e := range errs {
+				got = append(got, e.Field+":"+e.Rule)
+			}
+			assert.Equal(t, testCase.wantErrs, got)
+		})
+	}
+}
+
+// TestValidate_Custom 测试注册自定义规则与消息
+func TestValidate_Custom(t *testing.T) {
+	RegisterValidationRule("test_even", func(field ValidationField) bool {
+		return field.Value.Int()%2 == 0
+	})
+	RegisterValidationMessage("zh", "test_even", "{field}必须是偶数")
+
+	value := struct {
+		Count int `json:"count" validate:"test_even"`
+	}{Count: 3}
+	var errs ValidationErrors
+	require.ErrorAs(t, Validate(value), &errs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "count必须是偶数", errs[0].Translate("zh"))
+	// 没有对应语言的模板时使用默认语言的模板
+	assert.Equal(t, "count必须是偶数", errs[0].Translate("en"))
+
+	value.Count = 4
+	assert.NoError(t, Validate(value))
+}
+
+// TestValidationError_Translate 测试错误消息的翻译
+func TestValidationError_Translate(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  *ValidationError
+		lang string
+		want string
+	}{
+		{
+			name: "zh",
+			err:  &ValidationError{Name: "name", Rule: "required"},
+			lang: "zh",
+			want: "name为必填项",
+		},
+		{
+			name: "en",
+			err:  &ValidationError{Name: "name", Rule: "required"},
+			lang: "EN",
+			want: "name is required",
+		},
+		{
+			name: "number",
+			err:  &ValidationError{Name: "age", Rule: "min", Param: "18"},
+			lang: "en",
+			want: "age must be at least 18",
+		},
+		{
+			name: "length",
+			err:  &ValidationError{Name: "name", Rule: "min", Param: "2", byLength: true},
+			lang: "en",
+			want: "name must be at least 2 characters or items long",
+		},
+		{
+			name: "unknown language",
+			err:  &ValidationError{Name: "name", Rule: "required"},
+			lang: "fr",
+			want: "name为必填项",
+		},
+		{
+			name: "fallback template",
+			err:  &ValidationError{Name: "name", Rule: "other"},
+			lang: "en",
+			want: "name failed on the other rule",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, testCase.err.Translate(testCase.lang))
+		})
+	}
+}
+
+// TestNegotiateLanguage 测试根据请求头 Accept-Language 选择语言
+func TestNegotiateLanguage(t *testing.T) {
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "empty", acceptLanguage: "", want: "zh"},
+		{name: "exact", acceptLanguage: "en", want: "en"},
+		{name: "primary", acceptLanguage: "en-US", want: "en"},
+		{name: "q value", acceptLanguage: "zh-CN;q=0.5, en;q=0.8", want: "en"},
+		{name: "skip unknown", acceptLanguage: "fr, en-GB;q=0.9", want: "en"},
+		{name: "rejected", acceptLanguage: "en;q=0", want: "zh"},
+		{name: "unknown", acceptLanguage: "fr, de", want: "zh"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, negotiateLanguage(testCase.acceptLanguage))
+		})
+	}
+}
+
+// TestContext_RespValidationErrors 测试校验失败时的响应
+func TestContext_RespValidationErrors(t *testing.T) {
+	s := NewHTTPServer()
+	s.POST("/users", func(ctx *Context) {
+		var req validateItem
+		err := ctx.BindJSON(&req)
+		var errs ValidationErrors
+		if errors.As(err, &errs) {
+			_ = ctx.RespValidationErrors(errs)
+			return
+		}
+		require.NoError(t, err)
+		ctx.RespStatusCode = http.StatusCreated
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"Tom","count":0}`))
+	request.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var problem ValidationProblem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, ValidationProblem{
+		Type:   "about:blank",
+		Title:  "Request validation failed",
+		Status: http.StatusBadRequest,
+		Errors: []ValidationProblemError{
+			{Field: "count", Rule: "min", Param: "1", Message: "count must be at least 1"},
+		},
+	}, problem)
+
+	// 校验通过
+	request = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"Tom","count":1}`))
+	recorder = httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+// TestContext_Bind_validate 测试绑定成功后自动校验
+func TestContext_Bind_validate(t *testing.T) {
+	var req struct {
+		Page int    `query:"page" validate:"min=1"`
+		Sort string `query:"sort" validate:"omitempty,oneof=asc desc"`
+	}
+
+	ctx := &Context{Req: httptest.NewRequest(http.MethodGet, "/?page=0&sort=up", nil)}
+	var errs ValidationErrors
+	require.ErrorAs(t, ctx.Bind(&req), &errs)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "page", errs[0].Name)
+	assert.Equal(t, "sort", errs[1].Name)
+
+	// 绑定失败时不做校验
+	ctx = &Context{Req: httptest.NewRequest(http.MethodGet, "/?page=x", nil)}
+	var bindErrs BindErrors
+	assert.ErrorAs(t, ctx.Bind(&req), &bindErrs)
+
+	ctx = &Context{Req: httptest.NewRequest(http.MethodGet, "/?page=2&sort=asc", nil)}
+	assert.NoError(t, ctx.Bind(&req))
+}